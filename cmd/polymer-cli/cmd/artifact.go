@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/jobs"
+	"github.com/stevenlei/polymer-cli/pkg/proof"
+)
+
+var statusExport string
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Check a proof artifact written by status --export and print its proof",
+	Long: `Check a proof artifact written by "polymer-cli status --export" and print its proof.
+
+The artifact is read from the given file, or from stdin when the file is -. Its
+proof must match the recorded checksum, pass the same checks as "verify" and,
+when the artifact records the request parameters, be for that log. Nothing is
+sent to the API, so this works on a machine without network access.
+
+The proof is printed to stdout, or written to --output-file, ready to be
+submitted to the destination chain.
+
+Examples:
+  polymer-cli import proof-12345.json
+  polymer-cli import proof-12345.json --output-file=proof.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := readArtifactFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("proof artifact for job %s is invalid: %w", a.JobID, err)
+		}
+		infof("Proof artifact for job %s is valid\n", a.JobID)
+
+		if outputFile != "" {
+			if err := writeFileAtomic(outputFile, []byte(a.Proof), "proof"); err != nil {
+				return err
+			}
+			infof("Proof written to %s\n", outputFile)
+			return nil
+		}
+
+		fmt.Println(a.Proof)
+		return nil
+	},
+}
+
+// newJobArtifact builds the export artifact of a completed job, taking the
+// request parameters from the job cache when the job was requested here
+func newJobArtifact(cfg config.Config, jobID string, status *api.ProofStatusResponse) (*proof.Artifact, error) {
+	proofText, err := formatProof(status.Proof, false)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := proof.NewArtifact(jobID, status.Status, proofText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export proof: %w", err)
+	}
+	a.APIURL = cfg.APIURL
+	a.CLIVersion = Version
+
+	store, err := openJobStore()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := store.Find(jobID)
+	switch {
+	case err == nil:
+		a.Request = &proof.ArtifactRequest{
+			ChainID:     entry.ChainID,
+			BlockNumber: entry.BlockNumber,
+			TxIndex:     entry.TxIndex,
+			LogIndex:    entry.LogIndex,
+			DestChainID: entry.DestChainID,
+		}
+	case errors.Is(err, jobs.ErrNotCached):
+		infof("Job %s is not in the job cache, exporting it without its request parameters\n", jobID)
+	default:
+		warnf("Failed to read the job cache, exporting job %s without its request parameters: %v\n", jobID, err)
+	}
+
+	return a, nil
+}
+
+// writeArtifactFile writes a to path atomically
+func writeArtifactFile(path string, a *proof.Artifact) error {
+	var buf bytes.Buffer
+	if err := a.Write(&buf); err != nil {
+		return fmt.Errorf("failed to encode proof artifact: %w", err)
+	}
+
+	return writeFileAtomic(path, buf.Bytes(), "proof artifact")
+}
+
+// readArtifactFile reads an artifact from path, or from stdin when path is -
+func readArtifactFile(path string) (*proof.Artifact, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proof artifact: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	return proof.ReadArtifact(r)
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the proof to this file instead of stdout")
+}
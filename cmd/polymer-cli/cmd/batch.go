@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+var batchFile string
+var batchConcurrency int
+var batchQuiet bool
+var batchJSON bool
+
+// batchRow is a single proof request read from a batch file
+type batchRow struct {
+	ChainID     uint64 `json:"chain-id"`
+	BlockNumber uint64 `json:"block-number"`
+	TxIndex     uint   `json:"tx-index"`
+	LogIndex    uint   `json:"log-index"`
+}
+
+// batchResult is the outcome of submitting a single batch row
+type batchResult struct {
+	Line  int
+	Row   batchRow
+	JobID string
+	Err   error
+}
+
+// batchOutput is the machine-readable form of a batch result
+type batchOutput struct {
+	Line int `json:"line"`
+	batchRow
+	JobID string `json:"jobID,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch --file=<path>",
+	Short: "Request proofs for many logs from a CSV or JSON file",
+	Long: `Request proofs for many logs from a CSV or JSON file.
+
+A CSV file has one request per line with the columns chain-id, block-number,
+tx-index and log-index. A header row with those names is optional:
+
+  chain-id,block-number,tx-index,log-index
+  11155420,24639225,4,1
+  11155420,24639230,0,0
+
+A JSON file contains an array of objects with the same keys, all required:
+
+  [{"chain-id": 11155420, "block-number": 24639225, "tx-index": 4, "log-index": 1}]
+
+Rows that fail validation are reported with their line in the summary and are
+not submitted.
+
+Requests are submitted concurrently and a summary table mapping each row to its
+job ID or error is printed, followed by a count of succeeded and failed
+requests on stderr unless --quiet is given. With --json the results are
+printed as a JSON array and the summary as a JSON object instead. The command
+exits non-zero if any request failed.
+
+Example:
+  polymer-cli batch --file=requests.csv --concurrency=8`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if batchFile == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if batchConcurrency <= 0 {
+			return fmt.Errorf("concurrency must be greater than 0")
+		}
+
+		// Load configuration
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Validate configuration
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// Read and validate the input rows
+		results, err := readBatchFile(batchFile)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no requests found in %s", batchFile)
+		}
+
+		// Create API client
+		client := newAPIClient(cfg)
+		if err := checkAuth(client, cfg); err != nil {
+			return err
+		}
+
+		debugf("Submitting %d proof requests with concurrency %d...\n", len(results), batchConcurrency)
+
+		submitBatch(client, results, batchConcurrency)
+
+		var summary jobSummary
+		for _, r := range results {
+			summary.add(r.Err)
+		}
+		if err := writeBatchResults(results); err != nil {
+			return err
+		}
+		if !batchQuiet {
+			logSummary(summary, batchJSON)
+		}
+
+		if summary.Failed > 0 {
+			return fmt.Errorf("%d of %d proof requests failed", summary.Failed, len(results))
+		}
+
+		return nil
+	},
+}
+
+// writeBatchResults prints the summary table mapping each row to its job ID
+// or error, or the results as a JSON array with --json
+func writeBatchResults(results []batchResult) error {
+	if batchJSON {
+		out := make([]batchOutput, len(results))
+		for i, r := range results {
+			out[i] = batchOutput{Line: r.Line, batchRow: r.Row, JobID: r.JobID}
+			if r.Err != nil {
+				out[i].Error = r.Err.Error()
+			}
+		}
+		return writeStructured(out, formatJSON)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tCHAIN ID\tBLOCK NUMBER\tTX INDEX\tLOG INDEX\tJOB ID / ERROR")
+	for _, r := range results {
+		outcome := r.JobID
+		if r.Err != nil {
+			outcome = "error: " + r.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%s\n",
+			r.Line, r.Row.ChainID, r.Row.BlockNumber, r.Row.TxIndex, r.Row.LogIndex, outcome)
+	}
+
+	return w.Flush()
+}
+
+// submitBatch requests a proof for every valid row using a bounded pool of workers.
+// Rows that already carry a validation error are skipped.
+func submitBatch(client *api.Client, results []batchResult, concurrency int) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				row := results[i].Row
+				jobID, err := client.RequestProof(row.ChainID, row.BlockNumber, row.TxIndex, row.LogIndex)
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].JobID = jobID
+			}
+		}()
+	}
+
+	for i := range results {
+		if results[i].Err == nil {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// readBatchFile parses a batch file, choosing the format from its extension
+func readBatchFile(path string) ([]batchResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch file: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return readBatchJSON(f)
+	case ".csv":
+		return readBatchCSV(f)
+	default:
+		return nil, fmt.Errorf("unsupported batch file extension %q, expected .csv or .json", filepath.Ext(path))
+	}
+}
+
+// batchKeys are the keys of a JSON batch row, in the order of the CSV columns
+var batchKeys = []string{"chain-id", "block-number", "tx-index", "log-index"}
+
+// readBatchJSON parses a JSON array of batch rows. Like CSV rows, rows that
+// fail validation are returned with their error set, and each row is
+// numbered by the line its object starts on.
+func readBatchJSON(r io.Reader) ([]batchResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON batch file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("failed to parse JSON batch file: expected an array of objects")
+	}
+
+	var results []batchResult
+	for decoder.More() {
+		// The offset is just past the previous element, so skip to this one
+		start := int(decoder.InputOffset())
+		for start < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+			start++
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON batch file: %w", err)
+		}
+
+		result := batchResult{Line: bytes.Count(data[:start], []byte("\n")) + 1}
+		result.Row, result.Err = parseBatchObject(raw)
+		results = append(results, result)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON batch file: %w", err)
+	}
+
+	return results, nil
+}
+
+// parseBatchObject converts a JSON batch row into a batch row, requiring
+// exactly the batchKeys with integer values
+func parseBatchObject(raw json.RawMessage) (batchRow, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return batchRow{}, fmt.Errorf("expected an object with the keys %s", strings.Join(batchKeys, ", "))
+	}
+
+	for key := range fields {
+		if !slices.Contains(batchKeys, key) {
+			return batchRow{}, fmt.Errorf("unknown key %q, expected %s", key, strings.Join(batchKeys, ", "))
+		}
+	}
+
+	// Hand the numbers to the CSV parser so both formats are checked alike
+	record := make([]string, len(batchKeys))
+	for i, key := range batchKeys {
+		value, ok := fields[key]
+		if !ok {
+			return batchRow{}, fmt.Errorf("missing %s", key)
+		}
+		var number json.Number
+		if err := json.Unmarshal(value, &number); err != nil {
+			return batchRow{}, fmt.Errorf("%s must be a number, got %s", key, value)
+		}
+		record[i] = number.String()
+	}
+
+	return parseBatchRecord(record)
+}
+
+// readBatchCSV parses CSV batch rows. Rows that fail validation are returned
+// with their error set so they show up in the summary instead of aborting the batch.
+// Each row is numbered by the line of the file it starts on, which differs
+// from its record number after blank lines or quoted fields spanning lines.
+func readBatchCSV(r io.Reader) ([]batchResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var results []batchResult
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV batch file: %w", err)
+		}
+
+		// Skip an optional header row
+		if first && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "chain-id") {
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		result := batchResult{Line: line}
+		result.Row, result.Err = parseBatchRecord(record)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// parseBatchRecord converts a CSV record into a batch row
+func parseBatchRecord(record []string) (batchRow, error) {
+	var row batchRow
+
+	if len(record) != 4 {
+		return row, fmt.Errorf("expected 4 columns, got %d", len(record))
+	}
+
+	chainID, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+	if err != nil {
+		return row, fmt.Errorf("invalid chain ID: %w", err)
+	}
+
+	blockNumber, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 64)
+	if err != nil {
+		return row, fmt.Errorf("invalid block number: %w", err)
+	}
+
+	txIndex, err := strconv.ParseUint(strings.TrimSpace(record[2]), 10, 32)
+	if err != nil {
+		return row, fmt.Errorf("invalid transaction index: %w", err)
+	}
+
+	logIndex, err := strconv.ParseUint(strings.TrimSpace(record[3]), 10, 32)
+	if err != nil {
+		return row, fmt.Errorf("invalid log index: %w", err)
+	}
+
+	row.ChainID = chainID
+	row.BlockNumber = blockNumber
+	row.TxIndex = uint(txIndex)
+	row.LogIndex = uint(logIndex)
+
+	return row, nil
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().StringVar(&batchFile, "file", "", "CSV or JSON file containing proof requests")
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", 4, "Number of proof requests to submit in parallel")
+	batchCmd.Flags().BoolVar(&batchQuiet, "quiet", false, "Do not print the summary line to stderr")
+	batchCmd.Flags().BoolVar(&batchJSON, "json", false, "Print the results as a JSON array and the summary line as a JSON object")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadBatchJSON(t *testing.T) {
+	input := `[
+  {"chain-id": 11155420, "block-number": 24639225, "tx-index": 4, "log-index": 1},
+  {"chain-id": 1, "blocknumber": 2, "tx-index": 3, "log-index": 4},
+  {"chain-id": 1, "tx-index": 3, "log-index": 4},
+  {"chain-id": "10", "block-number": "20", "tx-index": 3, "log-index": 4},
+  {"chain-id": 1, "block-number": -1, "tx-index": 3, "log-index": 4},
+  {"chain-id": 1, "block-number": 1.5, "tx-index": 3, "log-index": 4},
+  {"chain-id": 1, "block-number": true, "tx-index": 3, "log-index": 4},
+  {"chain-id": 1, "block-number": 2, "tx-index": 4294967296, "log-index": 4},
+  7,
+  {"chain-id": 1,
+   "block-number": 2, "tx-index": 3, "log-index": 4}
+]`
+
+	tests := []struct {
+		line int
+		row  batchRow
+		err  string
+	}{
+		{line: 2, row: batchRow{ChainID: 11155420, BlockNumber: 24639225, TxIndex: 4, LogIndex: 1}},
+		{line: 3, err: `unknown key "blocknumber"`},
+		{line: 4, err: "missing block-number"},
+		{line: 5, row: batchRow{ChainID: 10, BlockNumber: 20, TxIndex: 3, LogIndex: 4}},
+		{line: 6, err: "invalid block number"},
+		{line: 7, err: "invalid block number"},
+		{line: 8, err: "block-number must be a number, got true"},
+		{line: 9, err: "invalid transaction index"},
+		{line: 10, err: "expected an object"},
+		{line: 11, row: batchRow{ChainID: 1, BlockNumber: 2, TxIndex: 3, LogIndex: 4}},
+	}
+
+	results, err := readBatchJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchJSON() error: %v", err)
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("readBatchJSON() returned %d rows, want %d", len(results), len(tests))
+	}
+
+	for i, tt := range tests {
+		got := results[i]
+		if got.Line != tt.line {
+			t.Errorf("row %d: line = %d, want %d", i, got.Line, tt.line)
+		}
+		if tt.err == "" {
+			if got.Err != nil {
+				t.Errorf("line %d: unexpected error: %v", tt.line, got.Err)
+			} else if got.Row != tt.row {
+				t.Errorf("line %d: row = %+v, want %+v", tt.line, got.Row, tt.row)
+			}
+			continue
+		}
+		if got.Err == nil || !strings.Contains(got.Err.Error(), tt.err) {
+			t.Errorf("line %d: error = %v, want it to contain %q", tt.line, got.Err, tt.err)
+		}
+	}
+}
+
+func TestReadBatchJSONInvalidFile(t *testing.T) {
+	for _, input := range []string{`{"chain-id": 1}`, `[{"chain-id": 1`, `not json`, ``} {
+		if _, err := readBatchJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("readBatchJSON(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestReadBatchCSV(t *testing.T) {
+	input := "chain-id,block-number,tx-index,log-index\n11155420,24639225,4,1\n1,x,3,4\n1,2,3\n"
+
+	results, err := readBatchCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchCSV() error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("readBatchCSV() returned %d rows, want 3", len(results))
+	}
+
+	want := batchRow{ChainID: 11155420, BlockNumber: 24639225, TxIndex: 4, LogIndex: 1}
+	if results[0].Line != 2 || results[0].Err != nil || results[0].Row != want {
+		t.Errorf("first row = %+v, want line 2 with %+v", results[0], want)
+	}
+	if results[1].Line != 3 || results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "invalid block number") {
+		t.Errorf("second row = %+v, want an invalid block number error on line 3", results[1])
+	}
+	if results[2].Line != 4 || results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "expected 4 columns") {
+		t.Errorf("third row = %+v, want a column count error on line 4", results[2])
+	}
+}
+
+func TestReadBatchCSVLines(t *testing.T) {
+	// A blank line and a quoted field spanning two lines put the records
+	// on other lines than their record numbers
+	input := "chain-id,block-number,tx-index,log-index\n\n11155420,24639225,4,1\n\"1\n\",2,3,4\n1,x,3,4\n"
+
+	results, err := readBatchCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchCSV() error: %v", err)
+	}
+
+	want := []int{3, 4, 6}
+	if len(results) != len(want) {
+		t.Fatalf("readBatchCSV() returned %d rows, want %d", len(results), len(want))
+	}
+	for i, line := range want {
+		if results[i].Line != line {
+			t.Errorf("row %d: line = %d, want %d", i, results[i].Line, line)
+		}
+	}
+	if results[1].Err != nil || results[1].Row != (batchRow{ChainID: 1, BlockNumber: 2, TxIndex: 3, LogIndex: 4}) {
+		t.Errorf("multi-line row = %+v, want chain 1, block 2, tx 3, log 4", results[1])
+	}
+	if results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "invalid block number") {
+		t.Errorf("last row = %+v, want an invalid block number error", results[2])
+	}
+}
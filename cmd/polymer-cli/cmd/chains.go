@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+var chainsJSON bool
+
+// chainsCmd represents the chains command
+var chainsCmd = &cobra.Command{
+	Use:   "chains",
+	Short: "List the source chains the API can prove logs from",
+	Long: `List the source chains supported by the Polymer API, with their chain ID,
+name and status.
+
+If the API does not support listing its chains, a list bundled with
+polymer-cli is shown instead and a warning notes that it may be stale.
+
+Example:
+  polymer-cli chains
+  polymer-cli chains --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Validate configuration
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// Create API client
+		client := newAPIClient(cfg)
+
+		chains, err := client.GetSupportedChains()
+		if errors.Is(err, api.ErrMethodNotSupported) {
+			warnf("the API does not list its supported chains; showing the list bundled with polymer-cli, which may be stale\n")
+			chains = api.KnownChains
+		} else if err != nil {
+			return fmt.Errorf("failed to get supported chains: %w", err)
+		}
+
+		if chainsJSON {
+			if chains == nil {
+				chains = []api.ChainInfo{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(chains)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHAIN ID\tNAME\tSTATUS")
+		for _, c := range chains {
+			status := c.Status
+			if status == "" {
+				status = "-"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", c.ChainID, c.Name, status)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chainsCmd)
+
+	chainsCmd.Flags().BoolVar(&chainsJSON, "json", false, "Print the chains as a JSON array")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+// newAPIClient creates an API client configured from cfg
+func newAPIClient(cfg config.Config) *api.Client {
+	client := api.NewClient(cfg.APIKey, cfg.APIURL, time.Duration(cfg.Timeout)*time.Millisecond, cfg.Debug)
+	client.RetryMax = cfg.RetryMax
+	client.RetryBaseDelay = time.Duration(cfg.RetryBaseMs) * time.Millisecond
+	client.IntervalJitter = cfg.IntervalJitter
+	client.WaitTimeout = time.Duration(cfg.TimeoutWait) * time.Millisecond
+	client.PollErrorTolerance = cfg.PollErrorTolerance
+	client.PollBackoff = cfg.PollBackoff
+	client.PollMaxInterval = time.Duration(cfg.PollMaxInterval) * time.Millisecond
+	client.Logger = logger
+	client.TraceLog = traceLog
+	client.Timing = timingRecorder
+	client.UserAgent = userAgent(cfg)
+	client.Headers = extraHeaders(cfg)
+	client.HeaderOverride = cfg.HeaderOverride
+	client.HTTPClient.Transport = sharedTransport(cfg)
+	client.MaxResponseBytes = int64(cfg.MaxResponseBytes)
+	client.APIVersion = cfg.APIVersion
+	client.RequestMethod = cfg.MethodRequest
+	client.QueryMethod = cfg.MethodQuery
+	client.OnProofRequested = recordJob(cfg, client)
+
+	return client
+}
+
+// checkAuth pings the API when check-auth is enabled, so that an invalid API
+// key is reported before any slower work starts
+func checkAuth(client *api.Client, cfg config.Config) error {
+	if !cfg.CheckAuth {
+		return nil
+	}
+
+	debugf("Checking the API key...\n")
+	if err := client.Ping(); err != nil {
+		if errors.Is(err, api.ErrInvalidAPIKey) {
+			return err
+		}
+		return fmt.Errorf("failed to check the API key: %w", err)
+	}
+
+	return nil
+}
+
+// newRPCClient creates an RPC client for urls configured from cfg
+func newRPCClient(urls []string, cfg config.Config) *rpc.RPCClient {
+	client := rpc.NewRPCClient(urls, cfg.Debug)
+	client.Logger = logger
+	client.TraceLog = traceLog
+	client.Timing = timingRecorder
+	client.UserAgent = userAgent(cfg)
+	client.Headers = extraHeaders(cfg)
+	client.HeaderOverride = cfg.HeaderOverride
+	// LoadConfig has already rejected invalid credentials
+	client.AuthHeader, client.AuthValue, _ = cfg.RPCAuth()
+	client.HTTPClient.Transport = sharedTransport(cfg)
+	client.MaxResponseBytes = int64(cfg.MaxResponseBytes)
+
+	return client
+}
+
+// httpTransport is built once per invocation so that every API and RPC
+// client draws from the same pool of keep-alive connections
+var (
+	httpTransport *http.Transport
+	transportOnce sync.Once
+)
+
+// sharedTransport returns the HTTP transport shared by all clients, creating
+// it from cfg on first use
+func sharedTransport(cfg config.Config) *http.Transport {
+	transportOnce.Do(func() {
+		httpTransport = newTransport(cfg)
+	})
+
+	return httpTransport
+}
+
+// newTransport returns an HTTP transport for the API and RPC clients. It keeps
+// up to cfg.MaxIdleConnsPerHost idle connections per host for reuse, uses the
+// configured proxy, or the proxy environment variables when none is
+// configured, and applies the TLS settings. cfg.Proxy and cfg.CACert have
+// already been checked by Validate.
+func newTransport(cfg config.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	if transport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+	}
+
+	if cfg.Proxy != "" {
+		if proxyURL, err := url.Parse(cfg.Proxy); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	if cfg.CACert != "" || cfg.InsecureSkipVerify {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.CACert != "" {
+			if pool, err := config.LoadCertPool(cfg.CACert); err == nil {
+				tlsConfig.RootCAs = pool
+			}
+		}
+		if cfg.InsecureSkipVerify {
+			warnf("TLS certificate verification is disabled (--insecure-skip-verify); connections can be intercepted\n")
+			tlsConfig.InsecureSkipVerify = true
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport
+}
+
+// userAgent returns the configured User-Agent, defaulting to polymer-cli/<version>
+func userAgent(cfg config.Config) string {
+	if cfg.UserAgent != "" {
+		return cfg.UserAgent
+	}
+
+	return "polymer-cli/" + Version
+}
+
+// extraHeaders merges the config file headers with --header flags, which win
+func extraHeaders(cfg config.Config) http.Header {
+	if len(cfg.Headers) == 0 && len(headerFlags) == 0 {
+		return nil
+	}
+
+	headers := http.Header{}
+	for name, value := range cfg.Headers {
+		headers.Set(name, value)
+	}
+	for name, values := range headerFlags {
+		headers[name] = values
+	}
+
+	return headers
+}
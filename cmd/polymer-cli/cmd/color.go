@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+)
+
+var noColor bool
+
+// ANSI escape codes. Every color code has the same length, so that cells
+// colored differently still line up in a tabwriter column.
+const (
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorRed     = "\x1b[31m"
+	colorDefault = "\x1b[39m"
+	colorReset   = "\x1b[0m"
+)
+
+// colorEnabled reports whether stdout gets colored output: it must be a
+// terminal, and neither --no-color nor the NO_COLOR environment variable may
+// be set
+func colorEnabled() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isTerminal(os.Stdout)
+}
+
+// statusColor returns the color of a job status: green when complete, yellow
+// while pending or processing and red when failed
+func statusColor(status string) string {
+	switch api.ParseProofStatus(status) {
+	case api.ProofStatusComplete:
+		return colorGreen
+	case api.ProofStatusPending, api.ProofStatusProcessing:
+		return colorYellow
+	case api.ProofStatusFailed:
+		return colorRed
+	default:
+		return colorDefault
+	}
+}
+
+// colorCell wraps a table cell in color when color is on. Cells without a
+// color of their own, such as the header, get the default color so that every
+// cell in the column carries escape codes of the same length.
+func colorCell(text, color string, on bool) string {
+	if !on {
+		return text
+	}
+
+	return color + text + colorReset
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// configCmd groups the subcommands that read and write the config file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change settings in the config file",
+	Long: `Read and change settings in the config file.
+
+Examples:
+  polymer-cli config set interval 5000
+  polymer-cli config get api-url
+  polymer-cli config list`,
+}
+
+// configSetCmd writes one key to the active config file
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a key in the config file",
+	Long: `Set a key in the active config file, the one loaded by every other command, or
+create the file where init would when there is none.
+
+Other keys are kept. In a YAML file their order and comments are kept too; TOML
+and JSON files are rewritten by viper, which drops comments. The key is set at
+the top level of the file, not in a profile.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, raw := strings.ToLower(args[0]), args[1]
+		value, err := config.ParseValue(key, raw)
+		if err != nil {
+			return err
+		}
+
+		path := viper.ConfigFileUsed()
+		if path == "" {
+			if path, err = defaultConfigPath(); err != nil {
+				return err
+			}
+		}
+
+		if err := writeConfigKey(path, key, value); err != nil {
+			return err
+		}
+		infof("Set %s to %s in %s\n", key, raw, path)
+
+		// The active profile still wins over the value just written
+		if name := viper.GetString("profile"); name != "" {
+			if settings, ok := viper.GetStringMap("profiles")[strings.ToLower(name)].(map[string]interface{}); ok {
+				if _, ok := settings[key]; ok {
+					warnf("%s is also set by profile %q, which takes precedence\n", key, name)
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+// configGetCmd prints the effective value of one key
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a config key",
+	Long: `Print the effective value of a config key, after the config file, the selected
+profile, environment variables and flags are merged. The API key is printed as
+it is; use "config list" for a redacted view.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		value, err := cfg.Get(strings.ToLower(args[0]))
+		if err != nil {
+			return err
+		}
+
+		if headers, ok := value.(map[string]string); ok {
+			for _, name := range sortedKeys(headers) {
+				fmt.Printf("%s: %s\n", name, headers[name])
+			}
+			return nil
+		}
+
+		fmt.Println(value)
+		return nil
+	},
+}
+
+// configListCmd dumps the effective config
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the effective config as YAML",
+	Long: `Print every config key with its effective value as YAML, after the config file,
+the selected profile, environment variables and flags are merged. The API key
+and RPC credentials are redacted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.APIKey != "" {
+			cfg.APIKey = api.RedactAPIKey(cfg.APIKey)
+		}
+		cfg.RPCAuthHeader = redactCredentials(cfg.RPCAuthHeader, " ")
+		cfg.RPCBasicAuth = redactCredentials(cfg.RPCBasicAuth, ":")
+
+		// Encode a mapping node so the keys keep the order of the Config fields
+		doc := &yaml.Node{Kind: yaml.MappingNode}
+		for _, field := range cfg.Fields() {
+			var value yaml.Node
+			if err := value.Encode(field.Value); err != nil {
+				return fmt.Errorf("failed to encode %s: %w", field.Key, err)
+			}
+			doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Key}, &value)
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+		return encoder.Close()
+	},
+}
+
+// writeConfigKey sets key to value in the config file at path, creating the
+// file if it does not exist
+func writeConfigKey(path, key string, value interface{}) error {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "yaml" || ext == "yml" {
+		return writeYAMLConfigKey(path, key, value)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if _, err := os.Stat(path); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+	v.Set(key, value)
+	// The file may hold the API key, so keep a new one private to the user
+	v.SetConfigPermissions(0o600)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// writeYAMLConfigKey edits the YAML node tree rather than re-encoding the
+// settings, so the other keys keep their order and comments
+func writeYAMLConfigKey(path, key string, value interface{}) error {
+	mode := os.FileMode(0o600)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		// An empty file has no document yet
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a YAML mapping", path)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		// viper matches keys case-insensitively, so do the same here
+		if strings.EqualFold(root.Content[i].Value, key) {
+			old := root.Content[i+1]
+			valueNode.LineComment, valueNode.FootComment = old.LineComment, old.FootComment
+			root.Content[i+1] = &valueNode
+			found = true
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &valueNode)
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(out.String()), mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// redactCredentials masks what follows the first sep in value, e.g. the
+// token of "Bearer <token>" or the password of "user:password", or all of it
+// when there is no sep
+func redactCredentials(value, sep string) string {
+	if value == "" {
+		return ""
+	}
+	if prefix, secret, found := strings.Cut(value, sep); found {
+		return prefix + sep + api.RedactAPIKey(secret)
+	}
+
+	return api.RedactAPIKey(value)
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configListCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+var configDir string
+
+// configExtensions are the config file formats searched for, in order of precedence
+var configExtensions = []string{"yaml", "yml", "toml", "json"}
+
+// configLocation is a place searched for the config file: a file named
+// name.<ext> in dir, for any of configExtensions
+type configLocation struct {
+	dir  string
+	name string
+}
+
+// configLocations returns the places searched for the config file, in order.
+// With --config-dir or POLYMER_CONFIG_DIR only that directory is searched,
+// otherwise $XDG_CONFIG_HOME/polymer-cli, the home directory and then each
+// directory in $XDG_CONFIG_DIRS.
+func configLocations() ([]configLocation, error) {
+	if dir := configDirectory(); dir != "" {
+		return []configLocation{{dir, "config"}, {dir, ".polymer-cli"}}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	locations := []configLocation{
+		{filepath.Join(xdgConfigHome(home), "polymer-cli"), "config"},
+		{home, ".polymer-cli"},
+	}
+	for _, dir := range xdgConfigDirs() {
+		locations = append(locations, configLocation{filepath.Join(dir, "polymer-cli"), "config"})
+	}
+
+	return locations, nil
+}
+
+// configDirectory returns the directory given with --config-dir, or in
+// POLYMER_CONFIG_DIR, or an empty string if neither is set
+func configDirectory() string {
+	if configDir != "" {
+		return configDir
+	}
+
+	return os.Getenv(config.EnvVar("config-dir"))
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, or ~/.config when it is unset.
+// The base directory spec says relative paths are ignored.
+func xdgConfigHome(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(home, ".config")
+}
+
+// xdgConfigDirs returns the absolute directories in $XDG_CONFIG_DIRS, or
+// /etc/xdg when it is unset
+func xdgConfigDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("XDG_CONFIG_DIRS")) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"/etc/xdg"}
+	}
+
+	return dirs
+}
+
+// findConfigFile returns the first config file found in locations, or an
+// empty string if there is none
+func findConfigFile(locations []configLocation) string {
+	for _, loc := range locations {
+		for _, ext := range configExtensions {
+			path := filepath.Join(loc.dir, loc.name+"."+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// describeLocations lists the file patterns searched, e.g. for debug output
+func describeLocations(locations []configLocation) string {
+	patterns := make([]string, len(locations))
+	for i, loc := range locations {
+		patterns[i] = filepath.Join(loc.dir, loc.name+".{"+strings.Join(configExtensions, ",")+"}")
+	}
+
+	return strings.Join(patterns, ", ")
+}
+
+// defaultConfigPath returns where init writes the config file without
+// --config: config.yaml in the --config-dir directory, or in
+// $XDG_CONFIG_HOME/polymer-cli when XDG_CONFIG_HOME is set, and
+// ~/.polymer-cli.yaml otherwise
+func defaultConfigPath() (string, error) {
+	if dir := configDirectory(); dir != "" {
+		return filepath.Join(dir, "config.yaml"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	if filepath.IsAbs(os.Getenv("XDG_CONFIG_HOME")) {
+		return filepath.Join(xdgConfigHome(home), "polymer-cli", "config.yaml"), nil
+	}
+
+	return filepath.Join(home, ".polymer-cli.yaml"), nil
+}
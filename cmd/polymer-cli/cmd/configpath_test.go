@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// touch creates an empty file at path, along with its directory
+func touch(t *testing.T, path string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigLocations(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("POLYMER_CONFIG_DIR", "")
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_CONFIG_DIRS", "")
+
+		got, err := configLocations()
+		if err != nil {
+			t.Fatalf("configLocations() error: %v", err)
+		}
+		want := []configLocation{
+			{filepath.Join(home, ".config", "polymer-cli"), "config"},
+			{home, ".polymer-cli"},
+			{"/etc/xdg/polymer-cli", "config"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("configLocations() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("XDG variables", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "/xdg/home")
+		t.Setenv("XDG_CONFIG_DIRS", "/xdg/a:relative:/xdg/b")
+
+		got, err := configLocations()
+		if err != nil {
+			t.Fatalf("configLocations() error: %v", err)
+		}
+		want := []configLocation{
+			{"/xdg/home/polymer-cli", "config"},
+			{home, ".polymer-cli"},
+			{"/xdg/a/polymer-cli", "config"},
+			{"/xdg/b/polymer-cli", "config"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("configLocations() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("config directory", func(t *testing.T) {
+		t.Setenv("POLYMER_CONFIG_DIR", "/etc/polymer")
+
+		got, err := configLocations()
+		if err != nil {
+			t.Fatalf("configLocations() error: %v", err)
+		}
+		want := []configLocation{{"/etc/polymer", "config"}, {"/etc/polymer", ".polymer-cli"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("configLocations() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestFindConfigFile(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	locations := []configLocation{{first, "config"}, {second, ".polymer-cli"}}
+
+	if got := findConfigFile(locations); got != "" {
+		t.Errorf("findConfigFile() with no files = %q, want none", got)
+	}
+
+	// Within a location, YAML wins over TOML and TOML over JSON
+	touch(t, filepath.Join(second, ".polymer-cli.json"))
+	if got, want := findConfigFile(locations), filepath.Join(second, ".polymer-cli.json"); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+	touch(t, filepath.Join(second, ".polymer-cli.toml"))
+	if got, want := findConfigFile(locations), filepath.Join(second, ".polymer-cli.toml"); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+	touch(t, filepath.Join(second, ".polymer-cli.yml"))
+	if got, want := findConfigFile(locations), filepath.Join(second, ".polymer-cli.yml"); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+	touch(t, filepath.Join(second, ".polymer-cli.yaml"))
+	if got, want := findConfigFile(locations), filepath.Join(second, ".polymer-cli.yaml"); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+
+	// An earlier location wins whatever its format
+	touch(t, filepath.Join(first, "config.json"))
+	if got, want := findConfigFile(locations), filepath.Join(first, "config.json"); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+
+	// Directories with a config file's name are skipped
+	third := t.TempDir()
+	if err := os.Mkdir(filepath.Join(third, "config.yaml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConfigFile([]configLocation{{third, "config"}}); got != "" {
+		t.Errorf("findConfigFile() = %q, want a directory to be skipped", got)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/proof"
+)
+
+var decodeRaw bool
+
+// decodeCmd represents the decode command
+var decodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Print the contents of a proof",
+	Long: `Print the logical fields of a proof: source chain, block, transaction index,
+log index, emitting contract and event topics.
+
+The proof is read from --proof-file, or from stdin when no file is given, as
+base64 or 0x-prefixed hex. Use --raw to dump the underlying bytes as hex instead.
+
+Examples:
+  polymer-cli decode --proof-file=proof.txt
+  polymer-cli wait 12345 | polymer-cli decode --raw`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text, err := readProofInput(proofFile)
+		if err != nil {
+			return err
+		}
+
+		if decodeRaw {
+			data, err := proof.DecodeText(string(text))
+			if err != nil {
+				return fmt.Errorf("malformed proof encoding: %w", err)
+			}
+			fmt.Println("0x" + hex.EncodeToString(data))
+			return nil
+		}
+
+		p, err := proof.Parse(text)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Source chain ID\t%d\n", p.ChainID)
+		fmt.Fprintf(w, "Block number\t%d\n", p.BlockNumber)
+		fmt.Fprintf(w, "Transaction index\t%d\n", p.ReceiptIndex)
+		fmt.Fprintf(w, "Log index\t%d\n", p.LogIndex)
+		fmt.Fprintf(w, "Emitting contract\t0x%x\n", p.EmittingContract)
+		for i, topic := range p.Topics {
+			fmt.Fprintf(w, "Topic %d\t0x%x\n", i, topic)
+		}
+		fmt.Fprintf(w, "Data\t0x%x\n", p.Data)
+		fmt.Fprintf(w, "Polymer height\t%d\n", p.PolymerHeight)
+		fmt.Fprintf(w, "Polymer state root\t0x%x\n", p.StateRoot)
+		fmt.Fprintf(w, "Inclusion proof\t%d bytes\n", len(p.InclusionProof))
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(decodeCmd)
+
+	decodeCmd.Flags().StringVar(&proofFile, "proof-file", "", "File containing the proof (default: read from stdin)")
+	decodeCmd.Flags().BoolVar(&decodeRaw, "raw", false, "Dump the proof bytes as hex instead of decoding them")
+}
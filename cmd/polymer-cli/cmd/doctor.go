@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+var doctorRPCURLs []string
+
+// doctorCheck is the outcome of one diagnostic check
+type doctorCheck struct {
+	name string
+	// detail describes what was found; hint, when set, says how to fix a failure
+	detail string
+	hint   string
+	failed bool
+	// warning marks a failure that does not make the command fail
+	warning bool
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration and connectivity problems",
+	Long: `Check that polymer-cli is set up correctly: the config file can be read, an API
+key is configured, the configuration is valid and the Polymer API answers.
+With --rpc-url, each RPC endpoint is checked as well.
+
+Each check is printed as PASS, WARN or FAIL, with a hint on how to fix
+failures. The command exits non-zero if any check failed.
+
+Example:
+  polymer-cli doctor
+  polymer-cli doctor --rpc-url=https://sepolia.optimism.io`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var checks []doctorCheck
+		report := func(c doctorCheck) {
+			checks = append(checks, c)
+			printDoctorCheck(c)
+		}
+
+		report(checkConfigFile())
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			report(doctorCheck{name: "Config", detail: err.Error(), hint: "Fix the profile or config values named above", failed: true})
+			return doctorResult(checks)
+		}
+
+		// Check the key on its own first, as it is the most common problem
+		if err := cfg.ResolveAPIKey(); err != nil {
+			report(doctorCheck{name: "API key", detail: err.Error(), hint: "Make sure the API key file exists and is not empty", failed: true})
+		} else if cfg.APIKey == "" {
+			report(doctorCheck{name: "API key", detail: "not set", hint: "Set it with --api-key, --api-key-file, POLYMER_API_KEY or api-key in the config file", failed: true})
+		} else {
+			report(doctorCheck{name: "API key", detail: api.RedactAPIKey(cfg.APIKey)})
+		}
+
+		configValid := true
+		if err := cfg.Validate(); err != nil {
+			configValid = false
+			if cfg.APIKey != "" {
+				report(doctorCheck{name: "Config", detail: err.Error(), hint: "Fix the value named above in the config file, environment or flags", failed: true})
+			}
+		} else {
+			report(doctorCheck{name: "Config", detail: "valid"})
+		}
+
+		if configValid {
+			report(checkAPI(cfg))
+		} else {
+			report(doctorCheck{name: "API", detail: "skipped, the configuration is not valid", warning: true})
+		}
+
+		for _, url := range doctorRPCURLs {
+			report(checkRPC(url, cfg))
+		}
+
+		return doctorResult(checks)
+	},
+}
+
+// checkConfigFile reports which config file is used and whether it parses.
+// Having none is fine, since flags and environment variables work alone.
+func checkConfigFile() doctorCheck {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return doctorCheck{name: "Config file", detail: "none found, using flags and environment variables", hint: `Run "polymer-cli init" to create one`, warning: true}
+	}
+
+	// The root command ignores read errors, so read the file again to see them
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return doctorCheck{name: "Config file", detail: fmt.Sprintf("%s: %v", path, err), hint: "Fix the file, or point --config at another one", failed: true}
+	}
+
+	return doctorCheck{name: "Config file", detail: path}
+}
+
+// checkAPI pings the API once, without retries
+func checkAPI(cfg config.Config) doctorCheck {
+	client := newAPIClient(cfg)
+	client.RetryMax = 0
+
+	if err := client.Ping(); err != nil {
+		return doctorCheck{name: "API", detail: fmt.Sprintf("%s: %v", cfg.APIURL, err), hint: "Check --api-url, your network or proxy settings and that the API key is valid; rerun with --debug for details", failed: true}
+	}
+
+	return doctorCheck{name: "API", detail: cfg.APIURL + " is reachable"}
+}
+
+// checkRPC asks an RPC endpoint for its chain ID
+func checkRPC(url string, cfg config.Config) doctorCheck {
+	name := "RPC " + url
+	if err := validateRPCURLs([]string{url}); err != nil {
+		return doctorCheck{name: name, detail: err.Error(), hint: "Use an http(s) or ws(s) URL", failed: true}
+	}
+
+	chainID, err := newRPCClient([]string{url}, cfg).GetChainID()
+	if err != nil {
+		return doctorCheck{name: name, detail: err.Error(), hint: "Check that the URL is right and the node is up; rerun with --debug for details", failed: true}
+	}
+
+	return doctorCheck{name: name, detail: fmt.Sprintf("reachable, chain ID %d", chainID)}
+}
+
+// printDoctorCheck prints one check with its hint indented below it
+func printDoctorCheck(c doctorCheck) {
+	label := "PASS"
+	switch {
+	case c.warning:
+		label = "WARN"
+	case c.failed:
+		label = "FAIL"
+	}
+
+	fmt.Printf("[%s] %s: %s\n", label, c.name, c.detail)
+	if c.hint != "" && (c.failed || c.warning) {
+		fmt.Printf("       %s\n", c.hint)
+	}
+}
+
+// doctorResult fails when any check failed; warnings don't count
+func doctorResult(checks []doctorCheck) error {
+	failed := 0
+	for _, c := range checks {
+		if c.failed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringSliceVar(&doctorRPCURLs, "rpc-url", nil, "RPC URL to check; repeat or comma-separate to check several")
+}
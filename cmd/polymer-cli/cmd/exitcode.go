@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+// Exit codes returned by the CLI. They are part of the public interface, so
+// existing values must not change.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // any failure not covered below, including usage errors
+	ExitJobNotFound = 2
+	ExitConfig      = 3
+	ExitNetwork     = 4
+	ExitProofFailed = 5
+	ExitTimeout     = 6
+	ExitRateLimited = 7
+	ExitInterrupted = 130
+)
+
+// errInterrupted is returned when a command is stopped with Ctrl-C or SIGTERM
+var errInterrupted = errors.New("interrupted")
+
+// ExitCode maps an error returned by Execute to the process exit code
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, api.ErrJobNotFound):
+		return ExitJobNotFound
+	case errors.Is(err, config.ErrInvalidConfig), errors.Is(err, api.ErrInvalidAPIKey):
+		return ExitConfig
+	case errors.Is(err, api.ErrUnreachable), errors.Is(err, rpc.ErrUnreachable):
+		return ExitNetwork
+	case errors.Is(err, api.ErrProofFailed):
+		return ExitProofFailed
+	case errors.Is(err, api.ErrWaitTimeout):
+		return ExitTimeout
+	case errors.Is(err, errInterrupted):
+		return ExitInterrupted
+	case errors.Is(err, api.ErrRateLimited):
+		return ExitRateLimited
+	default:
+		return ExitError
+	}
+}
+
+// exitCodeNames are the stable names of the exit codes used by --error-format=json
+var exitCodeNames = map[int]string{
+	ExitError:       "error",
+	ExitJobNotFound: "job_not_found",
+	ExitConfig:      "invalid_config",
+	ExitNetwork:     "network",
+	ExitProofFailed: "proof_failed",
+	ExitTimeout:     "timeout",
+	ExitRateLimited: "rate_limited",
+	ExitInterrupted: "interrupted",
+}
+
+// errorMessage returns the message printed for err, with a hint for API
+// responses whose status code calls for one
+func errorMessage(err error) string {
+	var apiErr *api.APIError
+	if errors.Is(err, api.ErrRateLimited) && errors.As(err, &apiErr) {
+		if apiErr.RetryAfter > 0 {
+			return fmt.Sprintf("%s (the API asked to retry after %s)", err, apiErr.RetryAfter.Round(time.Second))
+		}
+		return fmt.Sprintf("%s (wait before retrying, or lower --concurrency)", err)
+	}
+
+	return err.Error()
+}
+
+// errorOutput is the machine-readable form of a failure
+type errorOutput struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// printError prints err to stderr as text, or as a JSON object naming its
+// exit code with --error-format=json
+func printError(err error) {
+	if errorFormat != formatJSON {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", errorMessage(err))
+		return
+	}
+
+	data, _ := json.Marshal(errorOutput{Error: errorMessage(err), Code: exitCodeNames[ExitCode(err)]})
+	fmt.Fprintln(os.Stderr, string(data))
+}
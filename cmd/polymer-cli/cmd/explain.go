@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/polymer"
+)
+
+var explain bool
+
+// explainStep numbers the steps printed with --explain
+var explainStep int
+
+// explainf prints the next step of the --explain narrative to stderr. Unlike
+// debug output it is shown at every log level, since it was asked for.
+func explainf(format string, args ...interface{}) {
+	if !explain {
+		return
+	}
+
+	explainStep++
+	logf("%d. %s", explainStep, fmt.Sprintf(format, args...))
+}
+
+// explainSubmission describes the JSON-RPC call a proof request is about to
+// make, with the meaning of each parameter
+func explainSubmission(client *api.Client, chainID, blockNumber uint64, txIndex, logIndex uint) {
+	if !explain {
+		return
+	}
+
+	body, err := client.RequestProofBody(chainID, blockNumber, txIndex, logIndex)
+	if err != nil {
+		return
+	}
+	var request api.JSONRPCRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return
+	}
+	params, err := json.Marshal(request.Params)
+	if err != nil {
+		return
+	}
+
+	names := "chain ID, block number, transaction index, log index"
+	if len(request.Params) > 4 {
+		names += ", destination chain ID"
+	}
+	explainf("Submitting %s to %s with params %s (%s)\n", request.Method, client.APIBaseURL, params, names)
+}
+
+// explainResolved describes how a transaction hash was resolved: where the
+// chain ID came from and why each log was selected
+func explainResolved(resolved *polymer.ResolvedTx, opts polymer.TxHashOptions) {
+	if !explain {
+		return
+	}
+
+	explainf("Fetched transaction %s and its receipt: block %d, transaction index %d, %d logs\n",
+		resolved.Receipt.TransactionHash, resolved.BlockNumber, resolved.TxIndex, len(resolved.Receipt.Logs))
+
+	switch resolved.ChainIDSource {
+	case polymer.ChainIDFromOptions:
+		explainf("Using chain ID %d from --chain-id\n", resolved.ChainID)
+	case polymer.ChainIDFromTransaction:
+		explainf("Resolved chain ID %d from the transaction\n", resolved.ChainID)
+	default:
+		explainf("Resolved chain ID %d from eth_chainId, as the transaction does not carry one\n", resolved.ChainID)
+	}
+
+	// Without --all-matches only the first match is proved
+	selected := resolved.LogIndices
+	if !opts.AllMatches && len(selected) > 1 {
+		selected = selected[:1]
+		explainf("Found %d matching logs; only the first is proved without --all-matches\n", len(resolved.LogIndices))
+	}
+
+	filtered := len(opts.EventSignatures) > 0 || opts.LogAddress != ""
+	for i, logIdx := range selected {
+		switch {
+		case interactive:
+			explainf("Selected log %d in the log picker\n", logIdx)
+		case opts.LogIndex != nil:
+			explainf("Selected log %d as given by --log-index\n", logIdx)
+		case filtered:
+			var why []string
+			if sig := resolved.MatchedSignatures[i]; sig != "" {
+				why = append(why, "matching "+sig)
+			}
+			if opts.LogAddress != "" {
+				why = append(why, "emitted by "+opts.LogAddress)
+			}
+			if opts.Occurrence > 0 {
+				explainf("Selected log %d, occurrence %d of the logs %s, as given by --event-occurrence\n", logIdx, opts.Occurrence, strings.Join(why, " and "))
+				continue
+			}
+			explainf("Selected log %d %s\n", logIdx, strings.Join(why, " and "))
+		default:
+			explainf("Selected log %d, the first log, as no --log-index, --event-signature or --log-address was given\n", logIdx)
+		}
+	}
+}
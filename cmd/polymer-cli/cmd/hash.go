@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+// hashCmd represents the hash command
+var hashCmd = &cobra.Command{
+	Use:   "hash [signature...]",
+	Short: "Print the topic hash of event signatures",
+	Long: `Print the Keccak256 topic hash of each event signature, one per line, as used
+to match logs with --event-signature. Signatures are canonicalized first, so
+parameter names, "indexed" and type aliases such as uint do not change the hash.
+
+When no signature is given they are read from stdin, one per line. No API key
+or RPC endpoint is needed.
+
+Examples:
+  polymer-cli hash "Transfer(address,address,uint256)"
+  polymer-cli hash < events.txt`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		signatures := args
+		if len(signatures) == 0 {
+			if isTerminal(os.Stdin) {
+				return errors.New("no event signature given, pass it as an argument or pipe it to stdin")
+			}
+
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read event signatures from stdin: %w", err)
+			}
+			signatures = rpc.SplitEventSignatures(string(data))
+			if len(signatures) == 0 {
+				return errors.New("no event signature found on stdin")
+			}
+		}
+
+		for _, sig := range signatures {
+			canonical, err := rpc.CanonicalEventSignature(sig)
+			if err != nil {
+				return err
+			}
+
+			hash, err := rpc.EventSignatureHash(canonical)
+			if err != nil {
+				return err
+			}
+
+			debugf("%s -> %s\n", sig, canonical)
+			fmt.Println(hash)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"golang.org/x/term"
+)
+
+var nonInteractive bool
+var forceInit bool
+var initMaxAttempts int
+var initInterval int
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a config file",
+	Long: `Create a config file with your API key, API URL and polling settings.
+
+By default you are prompted for each value; the API key is not echoed. The file
+is written to $HOME/.polymer-cli.yaml, or to $XDG_CONFIG_HOME/polymer-cli/config.yaml
+when XDG_CONFIG_HOME is set. Use --config-dir to write config.yaml to another
+directory, or --config to give the full path (its extension selects YAML, TOML
+or JSON).
+
+Use --non-interactive to take every value from flags instead, e.g. in scripts.
+An existing file is never overwritten unless --force is passed.
+
+Examples:
+  polymer-cli init
+  polymer-cli init --non-interactive --api-key=your-polymer-api-key --max-attempts=30`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := cfgFile
+		if path == "" {
+			var err error
+			if path, err = defaultConfigPath(); err != nil {
+				return err
+			}
+		}
+
+		if _, err := os.Stat(path); err == nil && !forceInit {
+			return fmt.Errorf("config file %s already exists, use --force to overwrite it", path)
+		}
+
+		// Start from the defaults, overridden by any flags
+		cfg := config.DefaultConfig()
+		cfg.APIKey = apiKey
+		cfg.APIURL = apiURL
+		if cmd.Flags().Changed("max-attempts") {
+			cfg.MaxAttempts = initMaxAttempts
+		}
+		if cmd.Flags().Changed("interval") {
+			cfg.Interval = initInterval
+		}
+
+		if !nonInteractive {
+			if err := promptConfig(&cfg); err != nil {
+				return err
+			}
+		}
+
+		// Validate a copy so a "file:" API key reference is written as-is
+		// rather than replaced with the resolved key
+		check := cfg
+		if err := check.Validate(); err != nil {
+			return err
+		}
+
+		// Write only the settings collected here, not every flag default
+		v := viper.New()
+		v.Set("api-key", cfg.APIKey)
+		v.Set("api-url", cfg.APIURL)
+		v.Set("max-attempts", cfg.MaxAttempts)
+		v.Set("interval", cfg.Interval)
+		// The file holds the API key, so keep it private to the user
+		v.SetConfigPermissions(0o600)
+
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("failed to create config directory: %w", err)
+			}
+		}
+
+		if err := v.WriteConfigAs(path); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+
+		infof("Config file written to %s\n", path)
+		return nil
+	},
+}
+
+// promptConfig asks for each setting on the terminal, keeping the current
+// value when the answer is empty
+func promptConfig(cfg *config.Config) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	key, err := promptSecret(reader, "API key", cfg.APIKey != "")
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		cfg.APIKey = key
+	}
+
+	url, err := promptLine(reader, "API URL", cfg.APIURL)
+	if err != nil {
+		return err
+	}
+	cfg.APIURL = url
+
+	cfg.MaxAttempts, err = promptInt(reader, "Max polling attempts", cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	cfg.Interval, err = promptInt(reader, "Polling interval in milliseconds", cfg.Interval)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// promptLine asks for a value, returning def when the answer is empty
+func promptLine(reader *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		logf("%s [%s]: ", label, def)
+	} else {
+		logf("%s: ", label)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def, nil
+	}
+
+	return line, nil
+}
+
+// promptInt asks for an integer value, returning def when the answer is empty
+func promptInt(reader *bufio.Reader, label string, def int) (int, error) {
+	answer, err := promptLine(reader, label, strconv.Itoa(def))
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.Atoi(answer)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", strings.ToLower(label), err)
+	}
+
+	return value, nil
+}
+
+// promptSecret asks for a value without echoing it when stdin is a terminal
+func promptSecret(reader *bufio.Reader, label string, hasDefault bool) (string, error) {
+	if hasDefault {
+		logf("%s [keep current]: ", label)
+	} else {
+		logf("%s: ", label)
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	secret, err := term.ReadPassword(fd)
+	logln()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", strings.ToLower(label), err)
+	}
+
+	return strings.TrimSpace(string(secret)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	defaults := config.DefaultConfig()
+	initCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Take all values from flags instead of prompting")
+	initCmd.Flags().BoolVar(&forceInit, "force", false, "Overwrite an existing config file")
+	initCmd.Flags().IntVar(&initMaxAttempts, "max-attempts", defaults.MaxAttempts, "Maximum number of polling attempts")
+	initCmd.Flags().IntVar(&initInterval, "interval", defaults.Interval, "Polling interval in milliseconds")
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/jobs"
+)
+
+var jobsJSON bool
+var jobsPage int
+var jobsPageSize int
+var jobsRemote bool
+
+// defaultRemotePageSize is the number of jobs fetched per API call when
+// listing every job with --remote and no --page-size
+const defaultRemotePageSize = 50
+
+// remoteJobRow lays out a row of the streamed job table. The columns have
+// fixed widths because rows are written a page at a time and cannot be
+// aligned against the rows that follow.
+const remoteJobRow = "%-10s  %-9s  %-20s  %-10s  %-12s  %-8s  %s\n"
+
+// jobStore is the local cache of requested jobs, opened on first use
+var jobStore *jobs.Store
+
+// openJobStore returns the job cache at its default location
+func openJobStore() (*jobs.Store, error) {
+	if jobStore != nil {
+		return jobStore, nil
+	}
+
+	path, err := jobs.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	jobStore = jobs.NewStore(path)
+
+	return jobStore, nil
+}
+
+// recordJob returns a hook that adds each requested job to the job cache.
+// The destination chain ID is read from client when each job is recorded.
+// Failing to record a job only prints a warning; the request itself succeeded.
+func recordJob(cfg config.Config, client *api.Client) func(string, uint64, uint64, uint, uint) {
+	return func(jobID string, chainID, blockNumber uint64, txIndex, logIndex uint) {
+		store, err := openJobStore()
+		if err == nil {
+			err = store.Add(jobs.Entry{
+				JobID:       jobID,
+				ChainID:     chainID,
+				BlockNumber: blockNumber,
+				TxIndex:     txIndex,
+				LogIndex:    logIndex,
+				DestChainID: client.DestChainID,
+				APIURL:      cfg.APIURL,
+				RequestedAt: time.Now().UTC(),
+			})
+		}
+		if err != nil {
+			warnf("failed to record job %s: %v\n", jobID, err)
+		}
+	}
+}
+
+// jobsCmd represents the jobs command
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List recently requested proof jobs",
+	Long: `List the proof jobs recently requested from this machine, most recent first.
+
+Every successful proof request is recorded in ~/.polymer-cli/jobs.json along
+with its parameters and time; the last 100 are kept. Use "status --last" to
+check the most recent one.
+
+Use --page-size to list the jobs a page at a time and --page to pick the page;
+a hint on stderr notes when older jobs remain.
+
+With --remote the jobs of the API key are listed from the API instead, which
+includes jobs requested from other machines. Without --page every page is
+fetched in turn and printed as it arrives, so long listings are not held in
+memory.
+
+Example:
+  polymer-cli jobs
+  polymer-cli jobs --json
+  polymer-cli jobs --page-size=20 --page=2
+  polymer-cli jobs --remote --page-size=100`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if jobsPage < 1 {
+			return fmt.Errorf("--page must be at least 1, got %d", jobsPage)
+		}
+		if jobsPageSize < 0 {
+			return fmt.Errorf("--page-size must be non-negative, got %d", jobsPageSize)
+		}
+		if cmd.Flags().Changed("page") && jobsPageSize == 0 && !jobsRemote {
+			return fmt.Errorf("--page requires --page-size")
+		}
+
+		if jobsRemote {
+			return listRemoteJobs(cmd.Flags().Changed("page"))
+		}
+
+		store, err := openJobStore()
+		if err != nil {
+			return err
+		}
+
+		entries, more, err := store.Recent((jobsPage-1)*jobsPageSize, jobsPageSize)
+		if err != nil {
+			return err
+		}
+		if more {
+			defer infof("More jobs available, use --page=%d to see older ones\n", jobsPage+1)
+		}
+
+		if jobsJSON {
+			if entries == nil {
+				entries = []jobs.Entry{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(entries)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "JOB ID\tREQUESTED\tCHAIN ID\tBLOCK NUMBER\tTX INDEX\tLOG INDEX")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\n",
+				e.JobID, e.RequestedAt.Local().Format(time.RFC3339), e.ChainID, e.BlockNumber, e.TxIndex, e.LogIndex)
+		}
+
+		return w.Flush()
+	},
+}
+
+// listRemoteJobs lists the jobs of the API key from the API. With onePage
+// only the page selected by --page is fetched; otherwise every page is
+// fetched and written out before the next one is requested.
+func listRemoteJobs(onePage bool) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	client := newAPIClient(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pageSize := jobsPageSize
+	if pageSize == 0 {
+		pageSize = defaultRemotePageSize
+	}
+
+	out := newJobWriter(os.Stdout, jobsJSON)
+	if onePage {
+		page, err := client.ListJobsContext(ctx, pageSize, (jobsPage-1)*pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to list jobs: %w", err)
+		}
+		for _, job := range page.Jobs {
+			if err := out.add(job); err != nil {
+				return err
+			}
+		}
+		if err := out.close(); err != nil {
+			return err
+		}
+		if page.More {
+			infof("More jobs available, use --page=%d to see older ones\n", jobsPage+1)
+		}
+		return nil
+	}
+
+	err = client.EachJob(ctx, pageSize, out.add)
+	if closeErr := out.close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// jobWriter writes API jobs one at a time, either as table rows or as the
+// elements of a JSON array, so a listing never has to be held in memory
+type jobWriter struct {
+	w      io.Writer
+	asJSON bool
+	count  int
+}
+
+// newJobWriter returns a jobWriter on w; with asJSON it writes a JSON array
+func newJobWriter(w io.Writer, asJSON bool) *jobWriter {
+	jw := &jobWriter{w: w, asJSON: asJSON}
+	if !asJSON {
+		fmt.Fprintf(w, remoteJobRow, "JOB ID", "STATUS", "CREATED", "CHAIN ID", "BLOCK NUMBER", "TX INDEX", "LOG INDEX")
+	}
+	return jw
+}
+
+// add writes a single job
+func (jw *jobWriter) add(job api.JobSummary) error {
+	jw.count++
+	if !jw.asJSON {
+		_, err := fmt.Fprintf(jw.w, remoteJobRow, job.JobID, job.Status, job.CreatedAt,
+			fmt.Sprint(job.SrcChainID), fmt.Sprint(job.SrcBlockNumber), fmt.Sprint(job.TxIndex), fmt.Sprint(job.LogIndex))
+		return err
+	}
+
+	data, err := json.MarshalIndent(job, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if jw.count == 1 {
+		sep = "[\n  "
+	}
+	_, err = fmt.Fprintf(jw.w, "%s%s", sep, data)
+	return err
+}
+
+// close ends the JSON array; a table needs no ending
+func (jw *jobWriter) close() error {
+	if !jw.asJSON {
+		return nil
+	}
+	end := "\n]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+
+	jobsCmd.Flags().BoolVar(&jobsJSON, "json", false, "Print the jobs as a JSON array")
+	jobsCmd.Flags().IntVar(&jobsPage, "page", 1, "Page of jobs to list with --page-size, starting at 1")
+	jobsCmd.Flags().IntVar(&jobsPageSize, "page-size", 0, "Number of jobs per page (default: all, or 50 per API call with --remote)")
+	jobsCmd.Flags().BoolVar(&jobsRemote, "remote", false, "List the jobs of the API key from the API instead of the local cache")
+}
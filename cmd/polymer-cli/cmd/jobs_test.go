@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+)
+
+func TestJobWriterJSON(t *testing.T) {
+	for _, n := range []int{0, 1, 3} {
+		var buf bytes.Buffer
+		out := newJobWriter(&buf, true)
+		var want []api.JobSummary
+		for i := 0; i < n; i++ {
+			job := api.JobSummary{JobID: json.Number(strings.Repeat("1", i+1)), Status: "complete", SrcChainID: 11155420}
+			want = append(want, job)
+			if err := out.add(job); err != nil {
+				t.Fatalf("add() error: %v", err)
+			}
+		}
+		if err := out.close(); err != nil {
+			t.Fatalf("close() error: %v", err)
+		}
+
+		if n == 0 {
+			want = []api.JobSummary{}
+		}
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		if got := buf.String(); got != string(wantJSON)+"\n" {
+			t.Errorf("%d jobs: output = %q, want %q", n, got, string(wantJSON)+"\n")
+		}
+	}
+}
+
+func TestJobWriterTable(t *testing.T) {
+	var buf bytes.Buffer
+	out := newJobWriter(&buf, false)
+	if err := out.add(api.JobSummary{JobID: "42", Status: "pending", SrcChainID: 1, SrcBlockNumber: 2, TxIndex: 3, LogIndex: 4}); err != nil {
+		t.Fatalf("add() error: %v", err)
+	}
+	if err := out.close(); err != nil {
+		t.Fatalf("close() error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "JOB ID") || strings.Join(strings.Fields(lines[1]), " ") != "42 pending 1 2 3 4" {
+		t.Errorf("table = %q, want a header and one row for job 42", buf.String())
+	}
+}
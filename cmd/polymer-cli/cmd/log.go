@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+	"github.com/stevenlei/polymer-cli/pkg/timing"
+	"github.com/stevenlei/polymer-cli/pkg/tracelog"
+)
+
+// diagnostics receives progress and debug messages. Stdout is reserved for
+// the command payload (job IDs, statuses and proofs) so it can be piped.
+var diagnostics io.Writer = os.Stderr
+
+// logger prints leveled diagnostic messages to diagnostics. It is set up from
+// --log-level before any command runs and shared with the API and RPC clients.
+var logger = logging.New(diagnostics, logging.LevelInfo)
+
+// traceLog records API and RPC traces when --log-file is set; nil otherwise
+var traceLog *tracelog.Logger
+
+// timingRecorder reports call durations when --timing is set; nil otherwise
+var timingRecorder *timing.Recorder
+
+// openTraceLog starts appending traces to path, creating it if needed
+func openTraceLog(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	// The file stays open for the lifetime of the process
+	traceLog = tracelog.New(f)
+	return nil
+}
+
+// logf writes a formatted diagnostic message
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(diagnostics, format, args...)
+}
+
+// logln writes a diagnostic message followed by a newline
+func logln(args ...interface{}) {
+	fmt.Fprintln(diagnostics, args...)
+}
+
+// warnf writes a warning
+func warnf(format string, args ...interface{}) {
+	logger.Warnf(format, args...)
+}
+
+// infof writes a progress message, hidden at the warn and error log levels
+func infof(format string, args ...interface{}) {
+	logger.Infof(format, args...)
+}
+
+// debugf writes a debug message, shown at the debug and trace log levels
+func debugf(format string, args ...interface{}) {
+	logger.Debugf(format, args...)
+}
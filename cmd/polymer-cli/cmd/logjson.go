@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+var logJSON string
+
+// logJSONParamFlags are the request flags that --log-json replaces
+var logJSONParamFlags = []string{
+	"block-number", "block-hash", "tx-index", "log-index",
+	"tx-hash", "event-signature", "log-address",
+}
+
+// logObject is an EVM log as returned by eth_getLogs, optionally carrying the
+// ID of the chain it was read from
+type logObject struct {
+	rpc.Log
+	ChainID stdinValue `json:"chainId"`
+	Removed bool       `json:"removed"`
+}
+
+// applyLogJSON reads the log given with --log-json, or from stdin when it is
+// "-", and fills in the request flag variables from it. The log's logIndex
+// counts logs across the whole block, so unless the log belongs to the first
+// transaction of the block it is converted to the log's position in the
+// receipt, which needs --rpc-url.
+func applyLogJSON(cmd *cobra.Command, cfg config.Config) error {
+	for _, name := range logJSONParamFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be combined with --log-json", name)
+		}
+	}
+
+	data := []byte(logJSON)
+	if logJSON == "-" {
+		var err error
+		data, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return fmt.Errorf("--log-json must be a single JSON log object")
+	}
+
+	var evmLog logObject
+	if err := json.Unmarshal(data, &evmLog); err != nil {
+		return fmt.Errorf("failed to parse log JSON: %w", err)
+	}
+	if evmLog.Removed {
+		return fmt.Errorf("the log was removed by a chain reorganization")
+	}
+
+	var blockNum, txIdx, globalLogIdx uint64
+	fields := []struct {
+		name  string
+		value string
+		dest  *uint64
+	}{
+		{"blockNumber", evmLog.BlockNumber, &blockNum},
+		{"transactionIndex", evmLog.TransactionIndex, &txIdx},
+		{"logIndex", evmLog.LogIndex, &globalLogIdx},
+	}
+	for _, field := range fields {
+		// Pending logs have these fields set to null
+		if field.value == "" {
+			return fmt.Errorf("log JSON is missing %s", field.name)
+		}
+
+		n, err := rpc.HexToUint64(field.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s in log JSON: %w", field.name, err)
+		}
+		*field.dest = n
+	}
+
+	// No earlier transaction of the block emitted logs when this is the
+	// first one, so its block-wide logIndex is already the receipt position
+	receiptLogIdx := globalLogIdx
+	if txIdx > 0 {
+		if len(rpcURLs) == 0 {
+			return fmt.Errorf("the logIndex of a log counts every log in its block, not its position in the transaction receipt: pass --rpc-url to convert it, or --block-number, --tx-index and --log-index instead of --log-json")
+		}
+		if err := validateRPCURLs(rpcURLs); err != nil {
+			return err
+		}
+
+		var err error
+		receiptLogIdx, err = receiptLogIndex(newRPCClient(rpcURLs, cfg), evmLog.Log, blockNum, txIdx, globalLogIdx)
+		if err != nil {
+			return err
+		}
+		explainf("Converted block logIndex %d to log %d of the receipt of transaction %s\n", globalLogIdx, receiptLogIdx, evmLog.TransactionHash)
+	}
+
+	blockNumber = strconv.FormatUint(blockNum, 10)
+	txIndex = strconv.FormatUint(txIdx, 10)
+	logIndex = strconv.FormatUint(receiptLogIdx, 10)
+
+	// An explicit --chain-id wins over the chain ID carried by the log
+	if chainID == "" {
+		if evmLog.ChainID == "" {
+			return fmt.Errorf("chain ID is required: pass --chain-id or add a chainId field to the log JSON")
+		}
+
+		n, err := parseUint(string(evmLog.ChainID), 64)
+		if err != nil {
+			return fmt.Errorf("invalid chainId in log JSON: %w", err)
+		}
+		chainID = strconv.FormatUint(n, 10)
+	}
+
+	return nil
+}
+
+// receiptLogIndex returns the position in its transaction receipt of
+// evmLog, whose block-wide logIndex is globalLogIdx. The receipt is fetched
+// by the log's transactionHash and must be for the same block and
+// transaction index.
+func receiptLogIndex(rpcClient *rpc.RPCClient, evmLog rpc.Log, blockNum, txIdx, globalLogIdx uint64) (uint64, error) {
+	if evmLog.TransactionHash == "" {
+		return 0, fmt.Errorf("log JSON is missing transactionHash, needed to find the log in its transaction receipt")
+	}
+
+	receipt, err := rpcClient.GetTransactionReceipt(evmLog.TransactionHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get receipt of transaction %s: %w", evmLog.TransactionHash, err)
+	}
+	if receipt.BlockNumber == "" || receipt.TransactionIndex == "" {
+		return 0, fmt.Errorf("transaction %s is not mined", evmLog.TransactionHash)
+	}
+	receiptBlock, err := rpc.HexToUint64(receipt.BlockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number in receipt: %w", err)
+	}
+	receiptTxIdx, err := rpc.HexToUint64(receipt.TransactionIndex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid transaction index in receipt: %w", err)
+	}
+	if receiptBlock != blockNum || receiptTxIdx != txIdx {
+		return 0, fmt.Errorf("transaction %s is transaction %d of block %d, but the log JSON says transaction %d of block %d",
+			evmLog.TransactionHash, receiptTxIdx, receiptBlock, txIdx, blockNum)
+	}
+
+	for i, l := range receipt.Logs {
+		n, err := rpc.HexToUint64(l.LogIndex)
+		if err != nil {
+			return 0, fmt.Errorf("invalid logIndex of log %d in receipt: %w", i, err)
+		}
+		if n == globalLogIdx {
+			return uint64(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("transaction %s has no log with logIndex %d", evmLog.TransactionHash, globalLogIdx)
+}
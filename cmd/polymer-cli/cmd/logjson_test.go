@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+// receiptServer answers eth_getTransactionReceipt with transaction 4 of
+// block 0x177f6f9, whose three logs follow seven logs of earlier
+// transactions in the block
+func receiptServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || request.Method != "eth_getTransactionReceipt" {
+			t.Errorf("unexpected request %q: %v", body, err)
+			return
+		}
+		receipt := map[string]interface{}{
+			"transactionHash":  "0xabc",
+			"blockNumber":      "0x177f6f9",
+			"transactionIndex": "0x4",
+			"logs": []map[string]string{
+				{"logIndex": "0x7"},
+				{"logIndex": "0x8"},
+				{"logIndex": "0x9"},
+			},
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": receipt})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// resetLogJSONFlags clears the request flag variables applyLogJSON sets, now
+// and when the test ends
+func resetLogJSONFlags(t *testing.T) {
+	reset := func() {
+		logJSON, blockNumber, txIndex, logIndex, chainID, rpcURLs = "", "", "", "", "", nil
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestApplyLogJSON(t *testing.T) {
+	server := receiptServer(t)
+
+	tests := []struct {
+		name     string
+		log      string
+		rpcURL   string
+		txIndex  string
+		logIndex string
+		err      string
+	}{
+		{
+			name:     "converted with the receipt",
+			log:      `{"blockNumber":"0x177f6f9","transactionIndex":"0x4","logIndex":"0x8","transactionHash":"0xabc","chainId":"11155420"}`,
+			rpcURL:   server.URL,
+			txIndex:  "4",
+			logIndex: "1",
+		},
+		{
+			name:     "first transaction of the block",
+			log:      `{"blockNumber":"0x177f6f9","transactionIndex":"0x0","logIndex":"0x2","chainId":"11155420"}`,
+			txIndex:  "0",
+			logIndex: "2",
+		},
+		{
+			name: "no RPC URL",
+			log:  `{"blockNumber":"0x177f6f9","transactionIndex":"0x4","logIndex":"0x8","transactionHash":"0xabc","chainId":"11155420"}`,
+			err:  "pass --rpc-url to convert it",
+		},
+		{
+			name:   "no transaction hash",
+			log:    `{"blockNumber":"0x177f6f9","transactionIndex":"0x4","logIndex":"0x8","chainId":"11155420"}`,
+			rpcURL: server.URL,
+			err:    "log JSON is missing transactionHash",
+		},
+		{
+			name:   "log not in the receipt",
+			log:    `{"blockNumber":"0x177f6f9","transactionIndex":"0x4","logIndex":"0x1","transactionHash":"0xabc","chainId":"11155420"}`,
+			rpcURL: server.URL,
+			err:    "transaction 0xabc has no log with logIndex 1",
+		},
+		{
+			name:   "other transaction",
+			log:    `{"blockNumber":"0x177f6f9","transactionIndex":"0x5","logIndex":"0x8","transactionHash":"0xabc","chainId":"11155420"}`,
+			rpcURL: server.URL,
+			err:    "transaction 0xabc is transaction 4 of block 24639225, but the log JSON says transaction 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetLogJSONFlags(t)
+			logJSON = tt.log
+			if tt.rpcURL != "" {
+				rpcURLs = []string{tt.rpcURL}
+			}
+
+			err := applyLogJSON(requestCmd, config.DefaultConfig())
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("applyLogJSON() error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyLogJSON() error: %v", err)
+			}
+			if blockNumber != "24639225" || txIndex != tt.txIndex {
+				t.Errorf("block number, tx index = %s, %s, want 24639225, %s", blockNumber, txIndex, tt.txIndex)
+			}
+			if logIndex != tt.logIndex {
+				t.Errorf("log index = %s, want %s", logIndex, tt.logIndex)
+			}
+			if chainID != "11155420" {
+				t.Errorf("chain ID = %s, want 11155420", chainID)
+			}
+		})
+	}
+}
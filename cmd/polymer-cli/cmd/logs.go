@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+var logsTxHash string
+var logsRPCURLs []string
+var logsEventSignatures []string
+var logsSignaturesFile string
+var logsJSON bool
+
+// logInfo is one log of a transaction as listed by the logs command
+type logInfo struct {
+	Index   int    `json:"index"`
+	Address string `json:"address"`
+	Topic0  string `json:"topic0,omitempty"`
+	Event   string `json:"event,omitempty"`
+}
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "List the logs of a transaction",
+	Long: `List the logs emitted by a transaction with their index, contract address and
+first topic, to help pick the --log-index of a proof request. The index is the
+position of the log in the transaction receipt.
+
+Logs whose first topic matches a bundled common ERC-20, ERC-721, ERC-1155 or
+DEX event, or one given with --event-signature or --signatures-file, are shown
+with that event signature. No API key is needed.
+
+Example:
+  polymer-cli logs --tx-hash=0x123... --rpc-url=https://sepolia.optimism.io
+  polymer-cli logs --tx-hash=0x123... --rpc-url=https://... --event-signature="MyEvent(uint256)" --json
+  polymer-cli logs --tx-hash=0x123... --rpc-url=https://... --signatures-file=events.txt`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration; the API settings are not needed
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if logsTxHash == "" {
+			return fmt.Errorf("--tx-hash is required")
+		}
+		if len(logsRPCURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using transaction hash")
+		}
+		if err := validateRPCURLs(logsRPCURLs); err != nil {
+			return err
+		}
+
+		// Signatures given by the user take precedence over the bundled ones
+		signatures := append([]string{}, logsEventSignatures...)
+		if logsSignaturesFile != "" {
+			fromFile, err := readSignaturesFile(logsSignaturesFile)
+			if err != nil {
+				return err
+			}
+			signatures = append(signatures, fromFile...)
+		}
+
+		extra, err := eventSignatureNames(signatures)
+		if err != nil {
+			return err
+		}
+
+		rpcClient := newRPCClient(logsRPCURLs, cfg)
+		receipt, err := rpcClient.GetTransactionReceipt(logsTxHash)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction receipt: %w", err)
+		}
+		if receipt.TransactionHash == "" {
+			return fmt.Errorf("transaction %s not found or not yet mined", logsTxHash)
+		}
+
+		logs := describeLogs(receipt.Logs, extra)
+
+		if logsJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(logs)
+		}
+
+		if len(logs) == 0 {
+			infof("Transaction %s has no logs\n", logsTxHash)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "INDEX\tADDRESS\tTOPIC 0\tEVENT")
+		for _, l := range logs {
+			topic0, event := l.Topic0, l.Event
+			if topic0 == "" {
+				topic0 = "-"
+			}
+			if event == "" {
+				event = "-"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", l.Index, l.Address, topic0, event)
+		}
+
+		return w.Flush()
+	},
+}
+
+// eventSignatureNames maps the topic hash of each signature to its canonical
+// form. Each entry may list several comma or newline separated signatures.
+func eventSignatureNames(signatures []string) (map[string]string, error) {
+	names := map[string]string{}
+	for _, list := range signatures {
+		for _, sig := range rpc.SplitEventSignatures(list) {
+			canonical, err := rpc.CanonicalEventSignature(sig)
+			if err != nil {
+				return nil, err
+			}
+			hash, err := rpc.EventSignatureHash(canonical)
+			if err != nil {
+				return nil, err
+			}
+			names[hash] = canonical
+		}
+	}
+
+	return names, nil
+}
+
+// describeLogs lists the receipt logs with their first topic, named by the
+// signatures in extra or else by the bundled ones when it matches
+func describeLogs(logs []rpc.Log, extra map[string]string) []logInfo {
+	infos := make([]logInfo, len(logs))
+	for i, l := range logs {
+		infos[i] = logInfo{Index: i, Address: l.Address}
+		if len(l.Topics) == 0 {
+			continue
+		}
+
+		infos[i].Topic0 = l.Topics[0]
+		if sig, ok := extra[strings.ToLower(l.Topics[0])]; ok {
+			infos[i].Event = sig
+		} else if sig, ok := rpc.LookupEventSignature(l.Topics[0]); ok {
+			infos[i].Event = sig
+		}
+	}
+
+	return infos
+}
+
+// readSignaturesFile reads event signatures from path, one or more per line.
+// Blank lines and lines starting with # are skipped.
+func readSignaturesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signatures file: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringVar(&logsTxHash, "tx-hash", "", "Transaction hash to list the logs of")
+	logsCmd.Flags().StringSliceVar(&logsRPCURLs, "rpc-url", nil, "RPC URL for the blockchain; repeat or comma-separate to add fallback endpoints")
+	logsCmd.Flags().StringArrayVar(&logsEventSignatures, "event-signature", nil, "Event signature to name matching logs with, in addition to the bundled common events; repeatable")
+	logsCmd.Flags().StringVar(&logsSignaturesFile, "signatures-file", "", "File of event signatures, one per line, to name matching logs with")
+	logsCmd.Flags().BoolVar(&logsJSON, "json", false, "Print the logs as a JSON array")
+}
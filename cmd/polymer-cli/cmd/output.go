@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// formatFlag is the --format template text; outputTemplate is its parsed
+// form, or nil when no template was given
+var formatFlag string
+var outputTemplate *template.Template
+
+// parseOutputTemplate parses --format into outputTemplate
+func parseOutputTemplate() error {
+	outputTemplate = nil
+	if formatFlag == "" {
+		return nil
+	}
+
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(formatFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+
+	outputTemplate = tmpl
+	return nil
+}
+
+// writeTemplate prints v through the --format template, adding a newline
+// unless the template already ends with one
+func writeTemplate(v interface{}) error {
+	var b strings.Builder
+	if err := outputTemplate.Execute(&b, v); err != nil {
+		return fmt.Errorf("failed to execute --format template: %w", err)
+	}
+
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := fmt.Fprint(os.Stdout, out)
+	return err
+}
+
+// checkOutputFormat rejects anything but text, json and yaml
+func checkOutputFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatYAML:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q, expected text, json or yaml", format)
+	}
+}
+
+// writeStructured prints v to stdout as indented JSON or as YAML
+func writeStructured(v interface{}, format string) error {
+	if format == formatYAML {
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// proofDocument is a proof embedded in structured output. JSON output embeds
+// it as-is; YAML output turns a JSON document into the equivalent nested
+// structure and anything else into a plain string.
+type proofDocument json.RawMessage
+
+// MarshalJSON embeds the proof unchanged
+func (p proofDocument) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+
+	return p, nil
+}
+
+// MarshalYAML decodes the proof so it is not written as a byte sequence
+func (p proofDocument) MarshalYAML() (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(p, &v); err != nil {
+		return string(p), nil
+	}
+
+	return v, nil
+}
+
+// String returns the proof as printed in text output, unquoting a JSON string
+func (p proofDocument) String() string {
+	var s string
+	if err := json.Unmarshal(p, &s); err == nil {
+		return s
+	}
+
+	return string(p)
+}
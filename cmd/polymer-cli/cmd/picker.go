@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// errSelectionCancelled is returned when the user leaves the log picker
+// without choosing a log
+var errSelectionCancelled = errors.New("log selection cancelled")
+
+// pickLog lists logs on the terminal and lets the user move through them with
+// the arrow keys (or j and k) and choose one with Enter; q, Esc or Ctrl-C
+// cancel. It returns the position of the chosen log in logs. The list is drawn
+// on stderr and keys are read from stdin, which must both be terminals.
+func pickLog(logs []logInfo) (int, error) {
+	if len(logs) == 0 {
+		return 0, errors.New("no logs to choose from")
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from the terminal: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// Some terminals report no size at all; assume a common one then
+	width, height, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	// Show as many logs as fit below the prompt, scrolling to keep the
+	// cursor in view
+	visible := min(len(logs), max(height-2, 1))
+	cursor, offset, drawn := 0, 0, 0
+	draw := func() {
+		if drawn > 0 {
+			fmt.Fprintf(os.Stderr, "\r\x1b[%dA", drawn)
+		}
+
+		lines := []string{fmt.Sprintf("Select the log to prove (%d of %d, arrows to move, Enter to select, q to cancel):", cursor+1, len(logs))}
+		for i := offset; i < offset+visible; i++ {
+			marker := "  "
+			if i == cursor {
+				marker = "> "
+			}
+			lines = append(lines, marker+pickerLine(logs[i]))
+		}
+
+		for _, line := range lines {
+			// Lines that wrap would throw off the redraw, so cut them to the terminal
+			if width > 1 && len(line) > width-1 {
+				line = line[:width-1]
+			}
+			fmt.Fprintf(os.Stderr, "\x1b[2K%s\r\n", line)
+		}
+		drawn = len(lines)
+	}
+
+	buf := make([]byte, 8)
+	for {
+		draw()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read from the terminal: %w", err)
+		}
+
+		switch key := string(buf[:n]); key {
+		case "\x1b[A", "\x1bOA", "k":
+			if cursor > 0 {
+				cursor--
+			}
+		case "\x1b[B", "\x1bOB", "j":
+			if cursor < len(logs)-1 {
+				cursor++
+			}
+		case "\r", "\n":
+			return cursor, nil
+		case "q", "\x1b", "\x03":
+			return 0, errSelectionCancelled
+		}
+
+		if cursor < offset {
+			offset = cursor
+		} else if cursor >= offset+visible {
+			offset = cursor - visible + 1
+		}
+	}
+}
+
+// pickerLine describes a log on one line: its index, contract and event name,
+// or its first topic when the event is not known
+func pickerLine(l logInfo) string {
+	event := l.Event
+	if event == "" {
+		event = l.Topic0
+	}
+	if event == "" {
+		event = "(no topics)"
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%-4d %s  %s", l.Index, l.Address, event))
+}
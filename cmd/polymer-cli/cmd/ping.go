@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+// pingCmd represents the ping command
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check that the Polymer API is up and accepts the API key",
+	Long: `Send one lightweight JSON-RPC request to the Polymer API and print the round-trip
+latency. The request is not retried, so the latency is that of a single call.
+
+The command exits non-zero if the API cannot be reached or rejects the request.
+
+Example:
+  polymer-cli ping`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Validate configuration
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// Create API client
+		client := newAPIClient(cfg)
+		client.RetryMax = 0
+
+		start := time.Now()
+		if err := client.Ping(); err != nil {
+			return fmt.Errorf("failed to ping %s: %w", cfg.APIURL, err)
+		}
+
+		fmt.Printf("%s responded in %s\n", cfg.APIURL, time.Since(start).Round(time.Millisecond/10))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pingCmd)
+}
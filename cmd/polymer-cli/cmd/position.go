@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+var positionTxHash string
+var positionLogIndex string
+var positionRPCURLs []string
+var positionEventSignature string
+var positionJSON bool
+
+// logPosition is a log position as printed by the position command
+type logPosition struct {
+	*rpc.LogPosition
+	Event string `json:"event,omitempty"`
+}
+
+// positionCmd represents the position command
+var positionCmd = &cobra.Command{
+	Use:   "position",
+	Short: "Show where a log sits in its transaction and block",
+	Long: `Show how a proof request would address a log, computed offline from the
+transaction receipt without requesting a proof: the block number and
+transaction index, the log's position in the receipt (the --log-index of a
+request), its logIndex among all logs of the block, and its topics and data
+split into 32-byte words.
+
+With --event-signature, or for a bundled common event, the event is named.
+Topics are labelled with the parameters they hold when the signature given
+with --event-signature marks them indexed. No API key is needed.
+
+Example:
+  polymer-cli position --tx-hash=0x123... --log-index=1 --rpc-url=https://sepolia.optimism.io
+  polymer-cli position --tx-hash=0x123... --log-index=0 --rpc-url=https://... \
+    --event-signature="Transfer(address indexed from, address indexed to, uint256 value)" --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load configuration; the API settings are not needed
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if positionTxHash == "" {
+			return fmt.Errorf("--tx-hash is required")
+		}
+		if positionLogIndex == "" {
+			return fmt.Errorf("--log-index is required")
+		}
+		logIndex, err := parseUint(positionLogIndex, 32)
+		if err != nil {
+			return fmt.Errorf("invalid log index: %w", err)
+		}
+		if len(positionRPCURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using transaction hash")
+		}
+		if err := validateRPCURLs(positionRPCURLs); err != nil {
+			return err
+		}
+
+		var event *rpc.EventSignature
+		if positionEventSignature != "" {
+			event, err = rpc.ParseEventSignature(positionEventSignature)
+			if err != nil {
+				return err
+			}
+		}
+
+		rpcClient := newRPCClient(positionRPCURLs, cfg)
+		receipt, err := rpcClient.GetTransactionReceipt(positionTxHash)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction receipt: %w", err)
+		}
+		if receipt.TransactionHash == "" {
+			return fmt.Errorf("transaction %s not found or not yet mined", positionTxHash)
+		}
+
+		position, err := rpc.ComputeLogPosition(receipt, uint(logIndex), event)
+		if err != nil {
+			return err
+		}
+
+		result := logPosition{LogPosition: position}
+		var extra map[string]string
+		if event != nil {
+			extra, err = eventSignatureNames([]string{positionEventSignature})
+			if err != nil {
+				return err
+			}
+		}
+		result.Event = describeLogs(receipt.Logs[logIndex:logIndex+1], extra)[0].Event
+		if event != nil {
+			switch {
+			case len(position.Topics) == 0:
+				warnf("The log has no topics to match --event-signature against\n")
+			case result.Event == "":
+				warnf("The log's first topic does not match --event-signature\n")
+			case position.Topics[0].Param == "":
+				warnf("--event-signature has %d indexed parameters but the log has %d topics; topics are not labelled\n", countIndexed(event), len(position.Topics))
+			}
+		}
+
+		if positionJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+
+		printLogPosition(result)
+		return nil
+	},
+}
+
+// printLogPosition prints a log position as aligned text
+func printLogPosition(p logPosition) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Transaction:\t%s\n", p.TxHash)
+	fmt.Fprintf(w, "Block:\t%d (%s)\n", p.BlockNumber, p.BlockHash)
+	fmt.Fprintf(w, "Transaction index:\t%d\n", p.TxIndex)
+	fmt.Fprintf(w, "Log index:\t%d of %d in the transaction\n", p.LogIndex, p.TxLogCount)
+	fmt.Fprintf(w, "Block log index:\t%d (the transaction's logs start at %d)\n", p.GlobalLogIndex, p.FirstGlobalLogIndex)
+	fmt.Fprintf(w, "Address:\t%s\n", p.Address)
+	if p.Event != "" {
+		fmt.Fprintf(w, "Event:\t%s\n", p.Event)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTopics (%d):\n", len(p.Topics))
+	for _, topic := range p.Topics {
+		if topic.Param == "" {
+			fmt.Printf("  [%d]  %s\n", topic.Index, topic.Value)
+		} else {
+			fmt.Printf("  [%d]  %s  %s\n", topic.Index, topic.Value, topic.Param)
+		}
+	}
+
+	fmt.Printf("\nData (%d bytes):\n", p.DataBytes)
+	for _, word := range p.Data {
+		fmt.Printf("  [0x%02x]  %s\n", word.Offset, word.Value)
+	}
+}
+
+// countIndexed returns the number of indexed parameters of event
+func countIndexed(event *rpc.EventSignature) int {
+	n := 0
+	for _, param := range event.Params {
+		if param.Indexed {
+			n++
+		}
+	}
+
+	return n
+}
+
+func init() {
+	rootCmd.AddCommand(positionCmd)
+
+	positionCmd.Flags().StringVar(&positionTxHash, "tx-hash", "", "Transaction hash of the log")
+	positionCmd.Flags().StringVar(&positionLogIndex, "log-index", "", "Log index in the transaction (decimal or 0x hex)")
+	positionCmd.Flags().StringSliceVar(&positionRPCURLs, "rpc-url", nil, "RPC URL for the blockchain; repeat or comma-separate to add fallback endpoints")
+	positionCmd.Flags().StringVar(&positionEventSignature, "event-signature", "", "Event signature to name the log and its indexed topics with")
+	positionCmd.Flags().BoolVar(&positionJSON, "json", false, "Print the position as JSON")
+}
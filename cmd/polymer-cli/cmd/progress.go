@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+)
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// waitProgress renders a live "Waiting for proof..." line while polling.
+// A nil *waitProgress is valid and renders nothing.
+type waitProgress struct {
+	out         io.Writer
+	maxAttempts int
+	start       time.Time
+
+	mu      sync.Mutex
+	attempt int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startWaitProgress starts rendering progress to the diagnostics stream. It
+// returns nil when diagnostics are not going to a terminal, so piped output
+// stays free of control characters, or when --timing is printing lines.
+func startWaitProgress(maxAttempts int) *waitProgress {
+	// Timing lines would be overwritten by the redraws
+	if !isTerminal(diagnostics) || timingRecorder != nil {
+		return nil
+	}
+
+	p := &waitProgress{
+		out:         diagnostics,
+		maxAttempts: maxAttempts,
+		start:       time.Now(),
+		attempt:     1,
+		done:        make(chan struct{}),
+	}
+
+	// Redraw every second so the elapsed time keeps ticking between polls
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.render()
+			}
+		}
+	}()
+
+	p.render()
+	return p
+}
+
+// onPoll records the latest attempt; it matches the WaitForProof callback
+func (p *waitProgress) onPoll(attempt int, _ *api.ProofStatusResponse) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.attempt = attempt
+	p.mu.Unlock()
+
+	p.render()
+}
+
+// render redraws the progress line in place
+func (p *waitProgress) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := time.Since(p.start).Truncate(time.Second)
+	fmt.Fprintf(p.out, "\r\033[KWaiting for proof... %s (attempt %d/%d)", elapsed, p.attempt, p.maxAttempts)
+}
+
+// stop halts rendering and clears the progress line
+func (p *waitProgress) stop() {
+	if p == nil {
+		return
+	}
+
+	close(p.done)
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(p.out, "\r\033[K")
+}
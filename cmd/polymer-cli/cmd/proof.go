@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var outputFile string
+
+// formatProof returns the proof as it is printed. Proofs are usually returned
+// as a JSON string, which raw output unquotes; pretty output indents the JSON
+// and ends it with a newline.
+func formatProof(raw json.RawMessage, pretty bool) (string, error) {
+	if pretty {
+		var prettyJSON bytes.Buffer
+		if err := json.Indent(&prettyJSON, raw, "", "  "); err != nil {
+			return "", fmt.Errorf("failed to format proof as JSON: %w", err)
+		}
+		return prettyJSON.String() + "\n", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	// Not a valid JSON string; still drop surrounding quotes if there are any
+	rawStr := string(raw)
+	if len(rawStr) >= 2 && rawStr[0] == '"' && rawStr[len(rawStr)-1] == '"' {
+		rawStr = rawStr[1 : len(rawStr)-1]
+	}
+
+	return rawStr, nil
+}
+
+// writeProofFile writes the raw proof to path atomically, so an interrupted
+// run never leaves a truncated proof behind
+func writeProofFile(path string, proof json.RawMessage) error {
+	// Proofs are usually returned as a JSON string; write the unquoted value
+	text, err := formatProof(proof, false)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, []byte(text), "proof")
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as
+// path and renames it into place, creating parent directories as needed.
+// what names the contents in errors, e.g. "proof".
+func writeFileAtomic(path string, data []byte, what string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	// Clean up the temporary file on any failure before the rename
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set %s file permissions: %w", what, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", what, err)
+	}
+
+	return nil
+}
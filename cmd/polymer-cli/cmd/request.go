@@ -1,28 +1,51 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stevenlei/polymer-cli/pkg/api"
 	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+	"github.com/stevenlei/polymer-cli/pkg/polymer"
 	"github.com/stevenlei/polymer-cli/pkg/rpc"
 )
 
 var chainID string
+var destChainID string
 var blockNumber string
+var blockHash string
 var txIndex string
 var logIndex string
 var txHash string
-var rpcURL string
-var eventSignature string
+var rpcURLs []string
+var eventSignatures []string
+var logAddress string
+var eventOccurrence string
+var allMatches bool
+var interactive bool
 var waitForProof bool
 var returnRaw bool
+var printJobIDOnly bool
+var dryRun bool
+var validateIndices bool
+var requestOutputFormat string
+
+// requestOutput is the machine-readable result of a proof request. The status
+// and proof are only set once the proof has been waited for.
+type requestOutput struct {
+	JobID  string        `json:"jobID" yaml:"jobID"`
+	Status string        `json:"status,omitempty" yaml:"status,omitempty"`
+	Proof  proofDocument `json:"proof,omitempty" yaml:"proof,omitempty"`
+}
 
 // requestCmd represents the request command
 var requestCmd = &cobra.Command{
@@ -31,18 +54,49 @@ var requestCmd = &cobra.Command{
 	Long: `Request a new batch proof.
 
 You can specify the transaction either by providing the chain ID, block number, transaction index, and log index,
-or by providing a transaction hash (with an optional log index or event signature).
+or by providing a transaction hash (with an optional log index, event signature and/or emitting contract address).
 
 Examples using transaction parameters:
   polymer-cli request --chain-id=1 --block-number=17000000 --tx-index=5 --log-index=2
+  polymer-cli request --chain-id=1 --block-hash=0xabc... --tx-index=5 --log-index=2 --rpc-url=https://...
+  polymer-cli request --chain-id=1 --block-number=17000000 --tx-index=5 --log-index=1,2,4
 
 Examples using transaction hash:
   polymer-cli request --tx-hash=0x123... --log-index=1
   polymer-cli request --tx-hash=0x123... --event-signature="Transfer(address,address,uint256)"
+  polymer-cli request --tx-hash=0x123... --event-signature="Transfer(address,address,uint256)" --log-address=0xabc...
+  polymer-cli request --tx-hash=0x123... --event-signature="Transfer(address,address,uint256),Approval(address,address,uint256)"
+  polymer-cli request --tx-hash=0x123... --event-signature="Transfer(address,address,uint256)" --event-occurrence=3
+
+Use --interactive with --tx-hash to choose the log from a list of the transaction's logs,
+narrowed down by --event-signature and --log-address when they are given:
+  polymer-cli request --tx-hash=0x123... --rpc-url=https://... --interactive
 
 Use --wait to wait for the proof to be generated.
 
-The RPC URL is required when using --tx-hash, but not when providing direct transaction parameters.
+Use --print-job-id to write only the job ID to stdout whatever the debug and wait settings,
+so scripts can capture it while troubleshooting; with --wait the proof then goes to stderr.
+
+Use --output=json or --output=yaml to print the job ID, and with --wait the status and proof,
+as a JSON or YAML document. Use --format to print them through a Go template instead;
+the fields are .JobID, .Status and .Proof, the last two only set with --wait.
+
+Use --stdin to read the parameters as JSON instead of flags, either one object or an array of
+objects that are requested in turn, printing one job ID per line:
+  echo '{"chainId":1,"blockNumber":17000000,"txIndex":5,"logIndex":2}' | polymer-cli request --stdin
+  echo '[{"txHash":"0x123...","logIndex":1}]' | polymer-cli request --stdin --rpc-url=https://...
+
+Use --log-json to take the block number, transaction index and log index from an EVM log
+object, such as one entry of eth_getLogs output. Pass - to read it from stdin. The chain ID
+comes from --chain-id or a chainId field in the log. The log's logIndex counts logs across
+the whole block, while --log-index is the position in the transaction receipt, so unless the
+log is from the first transaction of the block, --rpc-url is required to look up the receipt
+by the log's transactionHash and convert it:
+  polymer-cli request --chain-id=1 --rpc-url=https://... --log-json='{"blockNumber":"0x1036640","transactionIndex":"0x5","logIndex":"0x2a","transactionHash":"0x123..."}'
+
+The RPC URL is required when using --tx-hash or --block-hash, but not when providing a block number.
+Multiple RPC URLs may be given (comma-separated or by repeating --rpc-url); they are tried in order
+and later ones are only used when earlier ones are unreachable or return a server error.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load configuration
@@ -56,237 +110,535 @@ The RPC URL is required when using --tx-hash, but not when providing direct tran
 			return err
 		}
 
+		if outputFile != "" && !waitForProof {
+			return fmt.Errorf("--output-file requires --wait")
+		}
+		for _, name := range []string{"max-attempts", "interval"} {
+			if cmd.Flags().Changed(name) && !waitForProof {
+				return fmt.Errorf("--%s requires --wait", name)
+			}
+		}
+
+		// Override config values with command-line flags if provided
+		if err := applyPollFlags(cmd, &cfg); err != nil {
+			return err
+		}
+		if dryRun && waitForProof {
+			return fmt.Errorf("--dry-run cannot be combined with --wait")
+		}
+		if err := checkOutputFormat(requestOutputFormat); err != nil {
+			return err
+		}
+		if dryRun && requestOutputFormat != formatText {
+			return fmt.Errorf("--dry-run cannot be combined with --output=%s", requestOutputFormat)
+		}
+		if err := parseOutputTemplate(); err != nil {
+			return err
+		}
+		if outputTemplate != nil && (dryRun || requestOutputFormat != formatText) {
+			return fmt.Errorf("--format cannot be combined with --dry-run or --output")
+		}
+		if printJobIDOnly && (dryRun || outputTemplate != nil || requestOutputFormat != formatText) {
+			return fmt.Errorf("--print-job-id cannot be combined with --dry-run, --output or --format")
+		}
+
 		// Create API client
-		client := api.NewClient(cfg.APIKey, cfg.APIURL, cfg.Debug)
+		client := newAPIClient(cfg)
+		if !dryRun {
+			if err := checkAuth(client, cfg); err != nil {
+				return err
+			}
+		}
 
-		// Check if the user provided a transaction hash
-		if txHash != "" {
-			// Ensure RPC URL is provided
-			if rpcURL == "" {
-				return fmt.Errorf("RPC URL is required when using transaction hash")
+		if logJSON != "" {
+			if readStdin {
+				return fmt.Errorf("--log-json cannot be combined with --stdin")
+			}
+			if err := applyLogJSON(cmd, cfg); err != nil {
+				return err
 			}
+		}
 
-			return processTransactionByHash(client, txHash, rpcURL, cfg, waitForProof, returnRaw)
+		if readStdin {
+			return requestFromStdin(cmd, client, cfg)
 		}
 
-		// Otherwise, proceed with chain ID, block number, etc.
-		// Check if required flags are provided
-		if chainID == "" || blockNumber == "" || txIndex == "" || logIndex == "" {
-			return fmt.Errorf("chain-id, block-number, tx-index, and log-index are required")
+		return requestFromFlags(client, cfg)
+	},
+}
+
+// requestFromFlags validates the request parameters held in the flag
+// variables and requests a proof for them
+func requestFromFlags(client *api.Client, cfg config.Config) error {
+	// The destination chain applies to whichever way the source log is given
+	client.DestChainID = 0
+	if destChainID != "" {
+		destChainIDUint, err := strconv.ParseUint(destChainID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid destination chain ID: %w", err)
+		}
+		if destChainIDUint == 0 {
+			return fmt.Errorf("destination chain ID must be greater than 0")
 		}
+		client.DestChainID = destChainIDUint
+	}
 
-		// Parse chain ID
-		chainIDUint, err := strconv.ParseUint(chainID, 10, 64)
+	if allMatches && (txHash == "" || waitForProof) {
+		return fmt.Errorf("--all-matches requires --tx-hash and cannot be combined with --wait")
+	}
+	if eventOccurrence != "" && (txHash == "" || (len(eventSignatures) == 0 && logAddress == "") || logIndex != "" || allMatches || interactive) {
+		return fmt.Errorf("--event-occurrence requires --tx-hash with --event-signature or --log-address, and cannot be combined with --log-index, --all-matches or --interactive")
+	}
+	if interactive {
+		if txHash == "" || logIndex != "" || allMatches || readStdin {
+			return fmt.Errorf("--interactive requires --tx-hash and cannot be combined with --log-index, --all-matches or --stdin")
+		}
+		if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+			return fmt.Errorf("--interactive needs a terminal; pass --log-index instead, listing the logs with \"polymer-cli logs\"")
+		}
+	}
+
+	// Check if the user provided a transaction hash
+	if txHash != "" {
+		// Ensure RPC URL is provided
+		if len(rpcURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using transaction hash")
+		}
+		if err := validateRPCURLs(rpcURLs); err != nil {
+			return err
+		}
+
+		return processTransactionByHash(client, txHash, rpcURLs, cfg, waitForProof, returnRaw)
+	}
+
+	// Otherwise, proceed with chain ID, block number, etc.
+	if blockNumber != "" && blockHash != "" {
+		return fmt.Errorf("only one of block-number and block-hash can be provided")
+	}
+
+	// Check if required flags are provided
+	if chainID == "" || (blockNumber == "" && blockHash == "") || txIndex == "" || logIndex == "" {
+		return fmt.Errorf("chain-id, block-number (or block-hash), tx-index, and log-index are required")
+	}
+
+	// Parse chain ID
+	chainIDUint, err := strconv.ParseUint(chainID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chain ID: %w", err)
+	}
+
+	// Parse block number, or resolve it from the block hash
+	var blockNumberUint uint64
+	if blockHash != "" {
+		if len(rpcURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using block hash")
+		}
+		if err := validateRPCURLs(rpcURLs); err != nil {
+			return err
+		}
+
+		blockNumberUint, err = resolveBlockHash(blockHash, rpcURLs, cfg)
 		if err != nil {
-			return fmt.Errorf("invalid chain ID: %w", err)
+			return err
+		}
+		explainf("Resolved block hash %s to block %d\n", blockHash, blockNumberUint)
+	} else if isBlockTag(blockNumber) {
+		if len(rpcURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using block tag %q", blockNumber)
+		}
+		if err := validateRPCURLs(rpcURLs); err != nil {
+			return err
 		}
 
-		// Parse block number
-		blockNumberUint, err := strconv.ParseUint(blockNumber, 10, 64)
+		blockNumberUint, err = resolveBlockTag(blockNumber, rpcURLs, cfg)
+		if err != nil {
+			return err
+		}
+		explainf("Resolved the %s block to block %d\n", blockNumber, blockNumberUint)
+	} else {
+		blockNumberUint, err = parseUint(blockNumber, 64)
 		if err != nil {
 			return fmt.Errorf("invalid block number: %w", err)
 		}
+	}
 
-		// Parse transaction index
-		txIndexUint, err := strconv.ParseUint(txIndex, 10, 32)
-		if err != nil {
-			return fmt.Errorf("invalid transaction index: %w", err)
+	// Parse transaction index
+	txIndexUint, err := parseUint(txIndex, 32)
+	if err != nil {
+		return fmt.Errorf("invalid transaction index: %w", err)
+	}
+
+	// Parse the log index, or several comma-separated ones
+	logIndexUints, err := parseLogIndices(logIndex)
+	if err != nil {
+		return err
+	}
+	if len(logIndexUints) > 1 && waitForProof {
+		return fmt.Errorf("several log indices cannot be combined with --wait")
+	}
+	if len(logIndexUints) > 1 {
+		indices := make([]string, len(logIndexUints))
+		for i, idx := range logIndexUints {
+			indices[i] = strconv.FormatUint(uint64(idx), 10)
 		}
+		explainf("Using chain ID %d, block %d, transaction index %d and log indices %s as given\n",
+			chainIDUint, blockNumberUint, txIndexUint, strings.Join(indices, ", "))
+	} else {
+		explainf("Using chain ID %d, block %d, transaction index %d and log index %d as given\n",
+			chainIDUint, blockNumberUint, txIndexUint, logIndexUints[0])
+	}
 
-		// Parse log index
-		logIndexUint, err := strconv.ParseUint(logIndex, 10, 32)
-		if err != nil {
-			return fmt.Errorf("invalid log index: %w", err)
+	if validateIndices {
+		if len(rpcURLs) == 0 {
+			return fmt.Errorf("RPC URL is required when using --validate")
+		}
+		if err := validateRPCURLs(rpcURLs); err != nil {
+			return err
 		}
 
-		// Request proof
-		fmt.Println("Requesting proof...")
-		jobID, err := client.RequestProof(
-			chainIDUint,
-			blockNumberUint,
-			uint(txIndexUint),
-			uint(logIndexUint),
-		)
-		if err != nil {
-			return fmt.Errorf("failed to request proof: %w", err)
+		for _, logIndexUint := range logIndexUints {
+			if err := checkIndices(rpcURLs, cfg, blockNumberUint, txIndexUint, uint64(logIndexUint)); err != nil {
+				return err
+			}
+			explainf("Checked that block %d has transaction %d with a log %d\n", blockNumberUint, txIndexUint, logIndexUint)
 		}
+	}
 
-		if cfg.Debug {
-			fmt.Println("Proof request submitted successfully")
-			fmt.Printf("Job ID: %s\n", jobID)
-		} else if !waitForProof {
-			// Only print the job ID in non-debug mode if not waiting for proof
-			fmt.Println(jobID)
+	if dryRun {
+		explainf("Printing what would be sent instead of submitting, as --dry-run was given\n")
+		for i, logIndexUint := range logIndexUints {
+			if i > 0 {
+				fmt.Println()
+			}
+			if err := printDryRun(client, chainIDUint, blockNumberUint, uint(txIndexUint), logIndexUint); err != nil {
+				return err
+			}
 		}
+		return nil
+	}
 
-		if !waitForProof {
-			return nil
+	// Request proofs for several logs of the transaction at once
+	if len(logIndexUints) > 1 {
+		infof("Requesting %d proofs...\n", len(logIndexUints))
+		explainf("Submitting %d proofs in one %s call to %s, falling back to one %s call per log if the API does not support it\n",
+			len(logIndexUints), api.RequestProofMultiMethod, client.APIBaseURL, cfg.MethodRequest)
+		jobIDs, err := client.RequestProofMulti(chainIDUint, blockNumberUint, txIndexUint, logIndexUints)
+		for i, jobID := range jobIDs {
+			debugf("Log %d: job ID %s\n", logIndexUints[i], jobID)
+		}
+		if err != nil && len(jobIDs) == 0 {
+			return fmt.Errorf("failed to request proofs: %w", err)
 		}
 
-		return waitAndDisplayProof(client, jobID, cfg, returnRaw)
-	},
-}
+		return printJobIDs(jobIDs, err)
+	}
+	logIndexUint := logIndexUints[0]
 
-// processTransactionByHash handles proof requests using a transaction hash
-func processTransactionByHash(client *api.Client, txHash, rpcURL string, cfg config.Config, waitForProof, returnRaw bool) error {
-	// Create RPC client
-	if cfg.Debug {
-		fmt.Printf("Connecting to RPC endpoint: %s\n", rpcURL)
+	// Request proof
+	infof("Requesting proof...\n")
+	explainSubmission(client, chainIDUint, blockNumberUint, uint(txIndexUint), logIndexUint)
+	jobID, err := client.RequestProof(
+		chainIDUint,
+		blockNumberUint,
+		uint(txIndexUint),
+		logIndexUint,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to request proof: %w", err)
 	}
-	rpcClient := rpc.NewRPCClient(rpcURL, cfg.Debug)
+	explainf("The API accepted the request as job %s\n", jobID)
 
-	// Fetch transaction details
-	if cfg.Debug {
-		fmt.Printf("Fetching transaction: %s\n", txHash)
+	debugf("Proof request submitted successfully\n")
+	debugf("Job ID: %s\n", jobID)
+
+	if !waitForProof {
+		// The job ID is the payload when not waiting for the proof
+		return printJobID(jobID)
 	}
-	tx, err := rpcClient.GetTransaction(txHash)
+
+	return waitAndDisplayProof(client, jobID, cfg, returnRaw)
+}
+
+// printDryRun shows the resolved proof request parameters and the body that
+// would be posted, without sending anything to the API
+func printDryRun(client *api.Client, chainID, blockNumber uint64, txIndex, logIndex uint) error {
+	body, err := client.RequestProofBody(chainID, blockNumber, txIndex, logIndex)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return err
 	}
 
-	// Fetch transaction receipt
-	if cfg.Debug {
-		fmt.Println("Fetching transaction receipt...")
+	fmt.Printf("Chain ID:           %d\n", chainID)
+	fmt.Printf("Block number:       %d\n", blockNumber)
+	fmt.Printf("Transaction index:  %d\n", txIndex)
+	fmt.Printf("Log index:          %d\n", logIndex)
+	if client.DestChainID != 0 {
+		fmt.Printf("Destination chain:  %d\n", client.DestChainID)
 	}
-	receipt, err := rpcClient.GetTransactionReceipt(txHash)
+	fmt.Printf("Request body:       %s\n", body)
+
+	return nil
+}
+
+// validateRPCURLs checks that every RPC URL is a valid http(s) or ws(s) URL
+func validateRPCURLs(urls []string) error {
+	for _, u := range urls {
+		if !config.IsHTTPURL(u) && !rpc.IsWebSocketURL(u) {
+			return fmt.Errorf("rpc-url must be a valid http(s) or ws(s) URL, got %q", u)
+		}
+	}
+
+	return nil
+}
+
+// resolveBlockHash looks up the number of the block with the given hash
+func resolveBlockHash(hash string, rpcURLs []string, cfg config.Config) (uint64, error) {
+	infof("Resolving block hash %s...\n", hash)
+	rpcClient := newRPCClient(rpcURLs, cfg)
+
+	block, err := rpcClient.GetBlockByHash(hash)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction receipt: %w", err)
+		return 0, fmt.Errorf("failed to get block: %w", err)
 	}
 
-	// Extract block number
-	blockNum, err := rpc.HexToUint64(receipt.BlockNumber)
+	number, err := rpc.HexToUint64(block.Number)
 	if err != nil {
-		return fmt.Errorf("invalid block number in receipt: %w", err)
+		return 0, fmt.Errorf("invalid block number in block: %w", err)
 	}
 
-	// Extract transaction index
-	txIdx, err := rpc.HexToUint64(receipt.TransactionIndex)
+	debugf("Block %s is number %d\n", hash, number)
+
+	return number, nil
+}
+
+// parseUint parses a decimal or 0x-prefixed hex flag value that must fit in
+// bitSize bits
+func parseUint(s string, bitSize int) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return strconv.ParseUint(s, 10, bitSize)
+	}
+
+	if len(s) == 2 {
+		return 0, fmt.Errorf("missing hex digits in %q", s)
+	}
+
+	value, err := rpc.HexToUint64(s)
 	if err != nil {
-		return fmt.Errorf("invalid transaction index in receipt: %w", err)
+		return 0, err
+	}
+	if bitSize < 64 && value >= 1<<uint(bitSize) {
+		return 0, fmt.Errorf("value %s out of range", s)
 	}
 
-	// Extract chain ID
-	var chainIDUint uint64
-	if tx.ChainID != "" {
-		// Try to extract from transaction
-		chainIDUint, err = rpc.HexToUint64(tx.ChainID)
-		if err != nil {
-			return fmt.Errorf("invalid chain ID in transaction: %w", err)
+	return value, nil
+}
+
+// blockTags are the --block-number values resolved through the RPC endpoint
+var blockTags = []string{"latest", "safe", "finalized"}
+
+// isBlockTag reports whether s is one of blockTags
+func isBlockTag(s string) bool {
+	for _, tag := range blockTags {
+		if s == tag {
+			return true
 		}
-	} else {
-		// If not found in transaction, prompt user to provide it
-		return fmt.Errorf("chain ID not found in transaction, please provide it with --chain-id flag")
 	}
+	return false
+}
+
+// resolveBlockTag looks up the number of the block a tag currently refers to
+func resolveBlockTag(tag string, rpcURLs []string, cfg config.Config) (uint64, error) {
+	infof("Resolving %s block...\n", tag)
+	rpcClient := newRPCClient(rpcURLs, cfg)
+
+	number, err := rpcClient.GetBlockNumberByTag(tag)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s block: %w", tag, err)
+	}
+
+	debugf("The %s block is number %d\n", tag, number)
 
-	// Determine which log to use
-	logIdx := uint(0)
-	logFound := false
+	return number, nil
+}
+
+// checkIndices confirms that the block has a transaction at txIdx and that its
+// receipt has a log at logIdx
+func checkIndices(rpcURLs []string, cfg config.Config, blockNum, txIdx, logIdx uint64) error {
+	infof("Validating transaction and log index against block %d...\n", blockNum)
+	rpcClient := newRPCClient(rpcURLs, cfg)
+
+	block, err := rpcClient.GetBlockByNumber(blockNum)
+	if err != nil {
+		return fmt.Errorf("failed to get block: %w", err)
+	}
+	if txIdx >= uint64(len(block.Transactions)) {
+		return fmt.Errorf("transaction index %d is out of range: block %d has %d transactions", txIdx, blockNum, len(block.Transactions))
+	}
 
-	if len(receipt.Logs) == 0 {
-		return fmt.Errorf("no logs found in transaction receipt")
+	hash := block.Transactions[txIdx]
+	receipt, err := rpcClient.GetTransactionReceipt(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	if logIdx >= uint64(len(receipt.Logs)) {
+		return fmt.Errorf("log index %d is out of range: transaction %s has %d logs", logIdx, hash, len(receipt.Logs))
+	}
+
+	debugf("Transaction %d is %s and has %d logs\n", txIdx, hash, len(receipt.Logs))
+
+	return nil
+}
+
+// processTransactionByHash handles proof requests using a transaction hash
+func processTransactionByHash(client *api.Client, txHash string, rpcURLs []string, cfg config.Config, waitForProof, returnRaw bool) error {
+	opts := polymer.TxHashOptions{
+		LogAddress: logAddress,
+		AllMatches: allMatches,
+	}
+
+	// Each --event-signature may itself list several comma or newline separated signatures
+	for _, list := range eventSignatures {
+		opts.EventSignatures = append(opts.EventSignatures, rpc.SplitEventSignatures(list)...)
+	}
+
+	// An explicit --chain-id wins over the chain ID of the transaction
+	if chainID != "" {
+		chainIDUint, err := strconv.ParseUint(chainID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid chain ID: %w", err)
+		}
+		opts.ChainID = chainIDUint
 	}
 
-	// Case 1: User specified log index
 	if logIndex != "" {
-		logIdxParsed, err := strconv.ParseUint(logIndex, 10, 32)
+		if strings.Contains(logIndex, ",") {
+			return fmt.Errorf("several log indices require --chain-id, --block-number and --tx-index instead of --tx-hash; use --all-matches to prove every matching log")
+		}
+		logIdxParsed, err := parseUint(logIndex, 32)
 		if err != nil {
 			return fmt.Errorf("invalid log index: %w", err)
 		}
+		idx := uint(logIdxParsed)
+		opts.LogIndex = &idx
+	}
 
-		if int(logIdxParsed) >= len(receipt.Logs) {
-			return fmt.Errorf("log index %d is out of range, transaction has %d logs", logIdxParsed, len(receipt.Logs))
+	if eventOccurrence != "" {
+		occurrence, err := parseUint(eventOccurrence, 32)
+		if err != nil {
+			return fmt.Errorf("invalid event occurrence: %w", err)
 		}
-
-		logIdx = uint(logIdxParsed)
-		logFound = true
-		if cfg.Debug {
-			fmt.Printf("Using specified log index: %d\n", logIdx)
+		if occurrence == 0 {
+			return fmt.Errorf("event occurrence must be at least 1")
 		}
+		opts.Occurrence = uint(occurrence)
 	}
 
-	// Case 2: User specified event signature
-	if eventSignature != "" && !logFound {
-		if cfg.Debug {
-			fmt.Printf("Searching for log with event signature: %s\n", eventSignature)
-		}
+	// Create RPC client
+	debugf("Connecting to RPC endpoint: %s\n", strings.Join(rpcURLs, ", "))
+	requester := polymer.NewProofRequester(client, newRPCClient(rpcURLs, cfg))
+
+	// Fetch transaction details and receipt, and pick the log(s) to prove.
+	// The picker offers every matching log, or every log without filters.
+	debugf("Fetching transaction and receipt: %s\n", txHash)
+	ctx := context.Background()
+	if interactive {
+		opts.AllMatches = true
+	}
+	resolved, err := requester.ResolveTxHash(ctx, txHash, opts)
+	if errors.Is(err, polymer.ErrChainIDUnknown) {
+		return fmt.Errorf("%w, please provide it with --chain-id flag", err)
+	}
+	if err != nil {
+		return err
+	}
 
-		// Normalize the event signature format
-		normalizedSig := strings.TrimSpace(eventSignature)
+	if interactive {
+		if err := pickResolvedLog(resolved, opts); err != nil {
+			return err
+		}
+	}
+	explainResolved(resolved, opts)
+
+	if logger.Enabled(logging.LevelDebug) {
+		switch resolved.ChainIDSource {
+		case polymer.ChainIDFromOptions:
+			debugf("Using chain ID %d from --chain-id\n", resolved.ChainID)
+		case polymer.ChainIDFromTransaction:
+			debugf("Using chain ID %d from the transaction\n", resolved.ChainID)
+		default:
+			debugf("Chain ID not found in transaction, using chain ID %d from eth_chainId\n", resolved.ChainID)
+		}
 
-		// Find matching log
-		for i, log := range receipt.Logs {
-			// Check if the topic matches the event signature
-			if len(log.Topics) > 0 {
-				// The first topic is the event signature hash
-				// Compare to expected signature (for debugging purposes)
-				if cfg.Debug {
-					fmt.Printf("  Log %d Topic[0]: %s\n", i, log.Topics[0])
+		if len(opts.EventSignatures) > 0 || logAddress != "" {
+			for i, log := range resolved.Receipt.Logs {
+				if len(log.Topics) > 0 {
+					debugf("  Log %d Address: %s Topic[0]: %s\n", i, log.Address, log.Topics[0])
 				}
+			}
+		}
 
-				// For more accurate matching, we should use the Keccak256 hash of the event signature
-				// But for now, we'll use a simpler approach that requires the API to add this feature
-				eventHash, err := rpcClient.GetEventSignatureHash(normalizedSig)
-				if err != nil {
-					return fmt.Errorf("failed to get event signature hash: %w", err)
-				}
+		debugf("Matching log indices: %v\n", resolved.LogIndices)
+		if len(opts.EventSignatures) > 1 {
+			for i, logIdx := range resolved.LogIndices {
+				debugf("  Log %d matched event signature %s\n", logIdx, resolved.MatchedSignatures[i])
+			}
+		}
+	}
 
-				if strings.EqualFold(log.Topics[0], eventHash) {
-					logIdx = uint(i)
-					logFound = true
-					if cfg.Debug {
-						fmt.Printf("Found matching log at index %d\n", i)
-					}
-					break
+	// Request a proof for every match, printing one job ID per line
+	if allMatches {
+		if dryRun {
+			explainf("Printing what would be sent instead of submitting, as --dry-run was given\n")
+			for i, logIdx := range resolved.LogIndices {
+				if i > 0 {
+					fmt.Println()
+				}
+				if err := printDryRun(client, resolved.ChainID, resolved.BlockNumber, uint(resolved.TxIndex), uint(logIdx)); err != nil {
+					return err
 				}
 			}
+			return nil
 		}
 
-		if !logFound {
-			return fmt.Errorf("no log found with event signature: %s", eventSignature)
+		explainf("Submitting %d proofs to %s with one %s call per log\n",
+			len(resolved.LogIndices), client.APIBaseURL, cfg.MethodRequest)
+		jobIDs, err := requester.RequestProofs(ctx, resolved, true)
+		for i, jobID := range jobIDs {
+			debugf("Log %d: job ID %s\n", resolved.LogIndices[i], jobID)
 		}
-	}
 
-	// Case 3: No log index or event signature provided, use the first log
-	if !logFound {
-		if cfg.Debug {
-			fmt.Println("No log index or event signature provided, using first log")
-		}
-		logIdx = 0
+		return printJobIDs(jobIDs, err)
 	}
 
+	logIdx := uint(resolved.LogIndices[0])
+
 	// Display the transaction details
-	if cfg.Debug {
-		fmt.Printf("Transaction details:\n")
-		fmt.Printf("  Chain ID: %d\n", chainIDUint)
-		fmt.Printf("  Block Number: %d\n", blockNum)
-		fmt.Printf("  Transaction Index: %d\n", txIdx)
-		fmt.Printf("  Log Index: %d\n", logIdx)
+	debugf("Transaction details:\n")
+	debugf("  Chain ID: %d\n", resolved.ChainID)
+	debugf("  Block Number: %d\n", resolved.BlockNumber)
+	debugf("  Transaction Index: %d\n", resolved.TxIndex)
+	debugf("  Log Index: %d\n", logIdx)
+
+	if dryRun {
+		explainf("Printing what would be sent instead of submitting, as --dry-run was given\n")
+		return printDryRun(client, resolved.ChainID, resolved.BlockNumber, uint(resolved.TxIndex), logIdx)
 	}
 
 	// Request proof
-	if cfg.Debug {
-		fmt.Println("Requesting proof...")
-	}
-	jobID, err := client.RequestProof(
-		chainIDUint,
-		blockNum,
-		uint(txIdx),
-		logIdx,
-	)
+	debugf("Requesting proof...\n")
+	explainSubmission(client, resolved.ChainID, resolved.BlockNumber, uint(resolved.TxIndex), logIdx)
+	jobIDs, err := requester.RequestProofs(ctx, resolved, false)
 	if err != nil {
-		return fmt.Errorf("failed to request proof: %w", err)
+		return err
 	}
+	jobID := jobIDs[0]
+	explainf("The API accepted the request as job %s\n", jobID)
 
-	if cfg.Debug {
-		fmt.Println("Proof request submitted successfully")
-		fmt.Printf("Job ID: %s\n", jobID)
-	} else if !waitForProof {
-		// Only print the job ID in non-debug mode if not waiting for proof
-		fmt.Println(jobID)
-	}
+	debugf("Proof request submitted successfully\n")
+	debugf("Job ID: %s\n", jobID)
 
 	if !waitForProof {
-		return nil
+		// The job ID is the payload when not waiting for the proof
+		return printJobID(jobID)
 	}
 
 	return waitAndDisplayProof(client, jobID, cfg, returnRaw)
@@ -294,65 +646,223 @@ func processTransactionByHash(client *api.Client, txHash, rpcURL string, cfg con
 
 // waitAndDisplayProof waits for a proof to be generated and displays it
 func waitAndDisplayProof(client *api.Client, jobID string, cfg config.Config, returnRaw bool) error {
+	// With --print-job-id the job ID is all that goes to stdout, so hand it
+	// over before waiting and leave the proof for stderr
+	if printJobIDOnly {
+		fmt.Println(jobID)
+	}
+
 	// Wait for proof to be generated
-	if cfg.Debug {
-		fmt.Printf("Waiting for proof to be generated (max %d attempts, %dms interval)...\n",
-			cfg.MaxAttempts, cfg.Interval)
+	explainf("Polling %s for job %s every %dms, up to %d times\n", cfg.MethodQuery, jobID, cfg.Interval, cfg.MaxAttempts)
+	debugf("Waiting for proof to be generated (max %d attempts, %dms interval)...\n",
+		cfg.MaxAttempts, cfg.Interval)
+
+	// Show a live progress line unless debug output already reports each poll
+	var progress *waitProgress
+	if !cfg.Debug && logger.Enabled(logging.LevelInfo) {
+		progress = startWaitProgress(cfg.MaxAttempts)
 	}
 
-	proofStatus, err := client.WaitForProof(jobID, cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond)
+	// Stop polling cleanly on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	proofStatus, err := client.WaitForProofContextWithCallback(ctx, jobID,
+		cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond, progress.onPoll)
+	progress.stop()
+	if errors.Is(err, context.Canceled) {
+		// The job keeps running, so hand over its ID instead of losing it
+		if !printJobIDOnly {
+			if err := printJobID(jobID); err != nil {
+				return err
+			}
+		}
+		logResumeHint(jobID)
+		return errInterrupted
+	}
 	if err != nil {
 		return fmt.Errorf("failed while waiting for proof: %w", err)
 	}
 
-	if cfg.Debug {
-		fmt.Println("Proof generated successfully!")
-	}
-
-	// Output proof
-	if !cfg.Debug || returnRaw {
-		// In non-debug mode, always use raw output
-		// In debug mode, use raw output if returnRaw is true
-		// Try to unmarshal if it's a JSON string
-		var s string
-		if err := json.Unmarshal(proofStatus.Proof, &s); err == nil {
-			// It's a JSON string, so use the unquoted value
-			fmt.Print(s)
-		} else {
-			// It's not a JSON string or there was an error
-			rawStr := string(proofStatus.Proof)
-			if len(rawStr) >= 2 && rawStr[0] == '"' && rawStr[len(rawStr)-1] == '"' {
-				rawStr = rawStr[1 : len(rawStr)-1]
-			}
-			fmt.Print(rawStr)
+	debugf("Proof generated successfully!\n")
+	if outputFile != "" {
+		explainf("Job %s is %s; writing the proof to %s\n", jobID, proofStatus.Status, outputFile)
+	} else {
+		explainf("Job %s is %s; printing the proof\n", jobID, proofStatus.Status)
+	}
+
+	// Write the proof to a file instead of stdout if requested
+	if outputFile != "" {
+		if err := writeProofFile(outputFile, proofStatus.Proof); err != nil {
+			return err
 		}
+		infof("Proof written to %s\n", outputFile)
+
+		// The proof is in the file, so leave it out of structured output
+		return writeRequestOutput(requestOutput{JobID: jobID, Status: proofStatus.Status})
+	}
+
+	if outputTemplate != nil || requestOutputFormat != formatText {
+		return writeRequestOutput(requestOutput{
+			JobID:  jobID,
+			Status: proofStatus.Status,
+			Proof:  embeddedProof(proofStatus.Proof),
+		})
+	}
+
+	// Output proof - raw in non-debug mode, and in debug mode with --raw
+	out, err := formatProof(proofStatus.Proof, cfg.Debug && !returnRaw)
+	if err != nil {
+		return err
+	}
+	if printJobIDOnly {
+		fmt.Fprint(os.Stderr, out)
 	} else {
-		// Format as pretty JSON (only in debug mode and returnRaw is false)
-		var prettyJSON bytes.Buffer
-		if err := json.Indent(&prettyJSON, proofStatus.Proof, "", "  "); err != nil {
-			return fmt.Errorf("failed to format proof as JSON: %w", err)
+		fmt.Print(out)
+	}
+
+	return nil
+}
+
+// printJobID prints a requested job ID on its own, or through --output or
+// --format
+func printJobID(jobID string) error {
+	if outputTemplate == nil && requestOutputFormat == formatText {
+		fmt.Println(jobID)
+		return nil
+	}
+
+	return writeRequestOutput(requestOutput{JobID: jobID})
+}
+
+// pickResolvedLog lets the user choose which log of resolved to prove and
+// narrows resolved down to it. Without filters every log of the receipt is
+// offered, otherwise only the matching ones.
+func pickResolvedLog(resolved *polymer.ResolvedTx, opts polymer.TxHashOptions) error {
+	names, err := eventSignatureNames(opts.EventSignatures)
+	if err != nil {
+		return err
+	}
+	all := describeLogs(resolved.Receipt.Logs, names)
+
+	candidates, matched := resolved.LogIndices, resolved.MatchedSignatures
+	if len(opts.EventSignatures) == 0 && opts.LogAddress == "" {
+		candidates = make([]int, len(all))
+		for i := range all {
+			candidates[i] = i
 		}
-		fmt.Println(prettyJSON.String())
+		matched = make([]string, len(all))
+	}
+
+	logs := make([]logInfo, len(candidates))
+	for i, idx := range candidates {
+		logs[i] = all[idx]
+	}
+
+	choice, err := pickLog(logs)
+	if err != nil {
+		return err
 	}
+	infof("Selected log %d\n", candidates[choice])
 
+	resolved.LogIndices = []int{candidates[choice]}
+	resolved.MatchedSignatures = []string{matched[choice]}
 	return nil
 }
 
+// printJobIDs prints one job ID per line, through --format for each job, or
+// as a JSON or YAML list with --output. reqErr is returned once the job IDs
+// are printed, so the jobs requested before a failure are not lost.
+func printJobIDs(jobIDs []string, reqErr error) error {
+	results := make([]requestOutput, len(jobIDs))
+	for i, jobID := range jobIDs {
+		results[i].JobID = jobID
+		switch {
+		case outputTemplate != nil:
+			if err := writeTemplate(results[i]); err != nil {
+				return err
+			}
+		case requestOutputFormat == formatText:
+			fmt.Println(jobID)
+		}
+	}
+
+	// Structured output lists the jobs requested before any failure
+	if requestOutputFormat != formatText {
+		if err := writeStructured(results, requestOutputFormat); err != nil {
+			return err
+		}
+	}
+
+	return reqErr
+}
+
+// parseLogIndices parses a --log-index value holding one log index, or
+// several separated by commas
+func parseLogIndices(s string) ([]uint, error) {
+	parts := strings.Split(s, ",")
+	indices := make([]uint, 0, len(parts))
+	seen := make(map[uint]bool, len(parts))
+	for _, part := range parts {
+		idx, err := parseUint(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log index: %w", err)
+		}
+		if seen[uint(idx)] {
+			return nil, fmt.Errorf("log index %d is given more than once", idx)
+		}
+		seen[uint(idx)] = true
+		indices = append(indices, uint(idx))
+	}
+
+	return indices, nil
+}
+
+// writeRequestOutput prints out through the --format template, or as a JSON
+// or YAML document with --output. It prints nothing for plain text output.
+func writeRequestOutput(out requestOutput) error {
+	switch {
+	case outputTemplate != nil:
+		return writeTemplate(out)
+	case requestOutputFormat != formatText:
+		return writeStructured(out, requestOutputFormat)
+	default:
+		return nil
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(requestCmd)
 
 	// Flags for direct proof requests
 	requestCmd.Flags().StringVar(&chainID, "chain-id", "", "Source chain ID")
-	requestCmd.Flags().StringVar(&blockNumber, "block-number", "", "Source block number")
-	requestCmd.Flags().StringVar(&txIndex, "tx-index", "", "Transaction index in the block")
-	requestCmd.Flags().StringVar(&logIndex, "log-index", "", "Log index in the transaction")
+	requestCmd.Flags().StringVar(&blockNumber, "block-number", "", "Source block number (decimal or 0x hex), or latest, safe or finalized (resolved via --rpc-url)")
+	requestCmd.Flags().StringVar(&blockHash, "block-hash", "", "Source block hash, resolved to a block number via --rpc-url")
+	requestCmd.Flags().StringVar(&txIndex, "tx-index", "", "Transaction index in the block (decimal or 0x hex)")
+	requestCmd.Flags().StringVar(&logIndex, "log-index", "", "Log index in the transaction (decimal or 0x hex); comma-separate several to request a proof for each")
+	requestCmd.Flags().StringVar(&destChainID, "dest-chain-id", "", "Destination chain ID the proof will be verified on (omitted from the request when not set)")
 
 	// Flags for transaction hash based requests
 	requestCmd.Flags().StringVar(&txHash, "tx-hash", "", "Transaction hash to request proof for")
-	requestCmd.Flags().StringVar(&rpcURL, "rpc-url", "", "RPC URL for the blockchain")
-	requestCmd.Flags().StringVar(&eventSignature, "event-signature", "", "Event signature to identify the log (e.g., 'Transfer(address,address,uint256)')")
+	requestCmd.Flags().StringSliceVar(&rpcURLs, "rpc-url", nil, "RPC URL for the blockchain; repeat or comma-separate to add fallback endpoints")
+	requestCmd.Flags().StringArrayVar(&eventSignatures, "event-signature", nil, "Event signature to identify the log (e.g., 'Transfer(address,address,uint256)'); repeat or comma-separate to match any of several events")
+	requestCmd.Flags().StringVar(&logAddress, "log-address", "", "Address of the contract that emitted the log, alone or combined with --event-signature")
+	requestCmd.Flags().StringVar(&eventOccurrence, "event-occurrence", "", "Prove the Nth log (1-based) matching --event-signature/--log-address instead of the first")
 
 	// Optional flags
 	requestCmd.Flags().BoolVar(&waitForProof, "wait", false, "Wait for the proof to be generated")
+	requestCmd.Flags().IntVar(&maxAttempts, "max-attempts", 0, "Maximum number of polling attempts with --wait (default: value from config)")
+	requestCmd.Flags().IntVar(&interval, "interval", 0, "Polling interval in milliseconds with --wait (default: value from config)")
+	requestCmd.Flags().BoolVar(&interactive, "interactive", false, "Choose the log to prove from a list of the transaction's logs (requires --tx-hash and a terminal)")
+	requestCmd.Flags().BoolVar(&allMatches, "all-matches", false, "Request a proof for every log matching --event-signature/--log-address and print all job IDs")
 	requestCmd.Flags().BoolVar(&returnRaw, "raw", false, "Return raw JSON output")
+	requestCmd.Flags().BoolVar(&validateIndices, "validate", false, "Check via --rpc-url that the transaction and log index exist before requesting the proof")
+	requestCmd.Flags().BoolVar(&printJobIDOnly, "print-job-id", false, "Write only the job ID to stdout, even with --debug or --wait; a proof waited for goes to stderr")
+	requestCmd.Flags().BoolVar(&explain, "explain", false, "Describe each step of the request on stderr: how the parameters were resolved, the log chosen and the call submitted")
+	requestCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Resolve the request parameters and print the request body without sending it")
+	requestCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the proof to this file instead of stdout (requires --wait)")
+	requestCmd.Flags().StringVar(&requestOutputFormat, "output", formatText, "Output format for the job ID and proof: text, json or yaml")
+	requestCmd.Flags().StringVar(&formatFlag, "format", "", "Go template to print the result with, e.g. '{{.JobID}} {{.Status}}'")
+	requestCmd.Flags().StringVar(&logJSON, "log-json", "", "EVM log object as JSON to take the block number, transaction index and log index from, or - to read it from stdin; its block-wide logIndex is converted to the receipt position with --rpc-url")
+	requestCmd.Flags().BoolVar(&readStdin, "stdin", false, "Read the request parameters from stdin as a JSON object, or an array of objects")
 }
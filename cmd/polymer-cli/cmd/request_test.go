@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateRPCURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		urls    []string
+		wantErr bool
+		// bad is the URL the error names
+		bad string
+	}{
+		{"http", []string{"http://localhost:8545"}, false, ""},
+		{"https", []string{"https://sepolia.optimism.io"}, false, ""},
+		{"ws", []string{"ws://localhost:8546"}, false, ""},
+		{"wss", []string{"wss://rpc.example.com/v1/key"}, false, ""},
+		{"mixed fallbacks", []string{"wss://rpc.example.com", "https://sepolia.optimism.io"}, false, ""},
+		{"none", nil, false, ""},
+		{"missing scheme", []string{"sepolia.optimism.io"}, true, "sepolia.optimism.io"},
+		{"bad scheme", []string{"ftp://rpc.example.com"}, true, "ftp://rpc.example.com"},
+		{"websocket typo", []string{"wws://rpc.example.com"}, true, "wws://rpc.example.com"},
+		{"empty host", []string{"https://"}, true, "https://"},
+		{"empty websocket host", []string{"wss://"}, true, "wss://"},
+		{"empty", []string{""}, true, ""},
+		{"bad fallback", []string{"https://sepolia.optimism.io", "localhost:8545"}, true, "localhost:8545"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRPCURLs(tt.urls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRPCURLs(%q) error = %v, want error: %v", tt.urls, err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "rpc-url must be a valid http(s) or ws(s) URL, got \""+tt.bad+"\"") {
+				t.Errorf("validateRPCURLs(%q) error = %q, want it to name %q", tt.urls, err, tt.bad)
+			}
+		})
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	tests := []struct {
+		value   string
+		bitSize int
+		want    uint64
+		err     string
+	}{
+		{value: "24639225", bitSize: 64, want: 24639225},
+		{value: "0x177f6f9", bitSize: 64, want: 24639225},
+		{value: "0X177F6F9", bitSize: 64, want: 24639225},
+		{value: "0", bitSize: 32, want: 0},
+		{value: "0x0", bitSize: 32, want: 0},
+		{value: "010", bitSize: 32, want: 10},
+		{value: "0xffffffff", bitSize: 32, want: 1<<32 - 1},
+		{value: "4294967295", bitSize: 32, want: 1<<32 - 1},
+		{value: "0xffffffffffffffff", bitSize: 64, want: 1<<64 - 1},
+		{value: "", bitSize: 64, err: "invalid syntax"},
+		{value: "0x", bitSize: 64, err: `missing hex digits in "0x"`},
+		{value: "0X", bitSize: 32, err: `missing hex digits in "0X"`},
+		{value: "0x100000000", bitSize: 32, err: "value 0x100000000 out of range"},
+		{value: "4294967296", bitSize: 32, err: "value out of range"},
+		{value: "0x10000000000000000", bitSize: 64, err: "too large for uint64"},
+		{value: "18446744073709551616", bitSize: 64, err: "value out of range"},
+		{value: "0xfg", bitSize: 64, err: "unexpected character 'g'"},
+		{value: "1a", bitSize: 64, err: "invalid syntax"},
+		{value: "x10", bitSize: 64, err: "invalid syntax"},
+		{value: "-1", bitSize: 64, err: "invalid syntax"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseUint(tt.value, tt.bitSize)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("parseUint(%q, %d) = %d, %v, want an error containing %q", tt.value, tt.bitSize, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUint(%q, %d) error: %v", tt.value, tt.bitSize, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseUint(%q, %d) = %d, want %d", tt.value, tt.bitSize, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogIndices(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []uint
+		err   string
+	}{
+		{value: "1", want: []uint{1}},
+		{value: "0x1", want: []uint{1}},
+		{value: "1, 0x2,3", want: []uint{1, 2, 3}},
+		{value: "1,0x1", err: "log index 1 is given more than once"},
+		{value: "1,", err: "invalid log index"},
+		{value: "0x", err: "invalid log index: missing hex digits"},
+		{value: "0x100000000", err: "invalid log index: value 0x100000000 out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseLogIndices(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("parseLogIndices(%q) = %v, %v, want an error containing %q", tt.value, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLogIndices(%q) error: %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLogIndices(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/jobs"
+)
+
+var resubmitForce bool
+
+// resubmitCmd represents the resubmit command
+var resubmitCmd = &cobra.Command{
+	Use:   "resubmit <jobID>",
+	Short: "Request a failed proof job again with the same parameters",
+	Long: `Request a new proof for a failed job, reusing the chain ID, block number,
+transaction index and log index recorded in the job cache when it was requested.
+
+The job must have been requested from this machine (see "polymer-cli jobs").
+Its status is checked first and only failed jobs are resubmitted, unless --force
+is given. The new job ID is printed.
+
+Example:
+  polymer-cli resubmit 12345
+  polymer-cli resubmit 12345 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobID := args[0]
+
+		// Load configuration
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Validate configuration
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// Look up the original request parameters
+		store, err := openJobStore()
+		if err != nil {
+			return err
+		}
+		entry, err := store.Find(jobID)
+		if errors.Is(err, jobs.ErrNotCached) {
+			return fmt.Errorf("the parameters of job %s are not in %s, request the proof again with \"polymer-cli request\"", jobID, store.Path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up job %s: %w", jobID, err)
+		}
+		if entry.APIURL != "" && entry.APIURL != cfg.APIURL {
+			warnf("job %s was requested from %s, resubmitting to %s\n", jobID, entry.APIURL, cfg.APIURL)
+		}
+
+		// Create API client
+		client := newAPIClient(cfg)
+
+		if !resubmitForce {
+			status, err := client.GetProofStatus(jobID)
+			if err != nil {
+				return fmt.Errorf("failed to get proof status: %w", err)
+			}
+			if status.State() != api.ProofStatusFailed {
+				return fmt.Errorf("job %s is %s, only failed jobs are resubmitted (use --force to resubmit anyway)", jobID, status.Status)
+			}
+		}
+
+		debugf("Resubmitting job %s: chain ID %d, block number %d, transaction index %d, log index %d\n",
+			jobID, entry.ChainID, entry.BlockNumber, entry.TxIndex, entry.LogIndex)
+
+		client.DestChainID = entry.DestChainID
+		newJobID, err := client.RequestProof(entry.ChainID, entry.BlockNumber, entry.TxIndex, entry.LogIndex)
+		if err != nil {
+			return fmt.Errorf("failed to request proof: %w", err)
+		}
+
+		fmt.Println(newJobID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resubmitCmd)
+
+	resubmitCmd.Flags().BoolVar(&resubmitForce, "force", false, "Resubmit the job even if it has not failed")
+}
@@ -2,16 +2,48 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+	"github.com/stevenlei/polymer-cli/pkg/timing"
 )
 
 var cfgFile string
+var configFileUsed bool
+
+// configSearched holds the places searched for the config file, when --config
+// was not given
+var configSearched []configLocation
 var apiKey string
+var apiKeyFile string
 var apiURL string
+var network string
 var debug bool
+var timeout int
+var timeoutWait int
+var logFile string
+var userAgentFlag string
+var headerArgs []string
+var headerOverride bool
+var rpcAuthHeader string
+var rpcBasicAuth string
+var proxy string
+var caCert string
+var insecureSkipVerify bool
+var apiVersion string
+var timingFlag bool
+var errorFormat string
+var checkAuthFlag bool
+var logLevel string
+
+// headerFlags holds the parsed --header values
+var headerFlags http.Header
+var profile string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -27,22 +59,134 @@ func Execute() error {
 	// Disable the completion command
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
-	return rootCmd.Execute()
+	// Errors are printed once below; usage is only shown for flag errors
+	rootCmd.SilenceErrors = true
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		rootCmd.SilenceUsage = true
+
+		if errorFormat != formatText && errorFormat != formatJSON {
+			return fmt.Errorf("invalid error format %q, expected text or json", errorFormat)
+		}
+
+		// A profile may set the log level. A missing profile is reported
+		// when the command loads its config, so it is ignored here.
+		_ = config.ApplyProfile()
+		level, err := config.LogLevel()
+		if err != nil {
+			return err
+		}
+		logger = logging.New(diagnostics, level)
+		if cmd.Flags().Changed("config") && cmd.Flags().Changed("config-dir") {
+			return fmt.Errorf("--config cannot be combined with --config-dir")
+		}
+		if configFileUsed {
+			infof("Using config file: %s\n", viper.ConfigFileUsed())
+		} else if configSearched != nil {
+			debugf("No config file found, searched %s\n", describeLocations(configSearched))
+		}
+
+		// --network beats an api-url from the config file or environment,
+		// but not one given with --api-url
+		if cmd.Flags().Changed("network") && !cmd.Flags().Changed("api-url") {
+			networkURL, err := config.NetworkAPIURL(network)
+			if err != nil {
+				return err
+			}
+			viper.Set("api-url", networkURL)
+		}
+
+		if err := parseHeaderFlags(); err != nil {
+			return err
+		}
+
+		if timingFlag {
+			timingRecorder = timing.New(diagnostics)
+		}
+
+		if logFile != "" {
+			return openTraceLog(logFile)
+		}
+		return nil
+	}
+
+	err := rootCmd.Execute()
+	timingRecorder.Summary()
+
+	if err != nil {
+		printError(err)
+	}
+
+	return err
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.polymer-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file in YAML, TOML or JSON format (default: first found in $XDG_CONFIG_HOME/polymer-cli, $HOME, $XDG_CONFIG_DIRS)")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "Directory to search for config.yaml/.toml/.json (or .polymer-cli.*) instead of the default locations")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Named profile from the config file to use")
 	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "Polymer API key")
+	rootCmd.PersistentFlags().StringVar(&apiKeyFile, "api-key-file", "", "File containing the Polymer API key")
 	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "https://proof.testnet.polymer.zone", "Polymer API URL")
-	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug logging")
+	rootCmd.PersistentFlags().StringVar(&network, "network", "", "Use the API URL of a network preset: "+strings.Join(config.NetworkNames(), " or ")+" (--api-url takes precedence)")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug logging, same as --log-level=debug")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Verbosity of messages on stderr: error, warn, info, debug or trace")
+	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", 60000, "HTTP timeout for API requests in milliseconds")
+	rootCmd.PersistentFlags().IntVar(&timeoutWait, "timeout-wait", 0, "Maximum total time to wait for a proof in milliseconds (0 means no limit)")
+
+	rootCmd.PersistentFlags().StringVar(&userAgentFlag, "user-agent", "", "User-Agent header for API and RPC requests (default \"polymer-cli/<version>\")")
+	rootCmd.PersistentFlags().StringArrayVar(&headerArgs, "header", nil, "Extra \"Key: Value\" header for API and RPC requests (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&headerOverride, "header-override", false, "Allow --header and headers to replace the Authorization and Content-Type headers")
+	rootCmd.PersistentFlags().StringVar(&rpcAuthHeader, "rpc-auth-header", "", "Credentials for RPC requests: an Authorization value such as \"Bearer <token>\", or a \"Key: Value\" header")
+	rootCmd.PersistentFlags().StringVar(&rpcBasicAuth, "rpc-basic-auth", "", "\"user:password\" to authenticate RPC requests with HTTP basic auth")
+	rootCmd.PersistentFlags().StringVar(&proxy, "proxy", "", "Proxy URL (http, https or socks5) for API and RPC requests (default: HTTP_PROXY/HTTPS_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&apiVersion, "api-version", "", "Polymer API version to request, sent as the X-API-Version header")
+	rootCmd.PersistentFlags().StringVar(&caCert, "ca-cert", "", "PEM bundle of extra CA certificates to trust for API and RPC requests")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Disable TLS certificate verification (unsafe, for testing only)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append API and RPC request/response traces to this file as JSON lines")
+	rootCmd.PersistentFlags().BoolVar(&timingFlag, "timing", false, "Print the duration of each API and RPC call, and their total, to stderr")
+	rootCmd.PersistentFlags().BoolVar(&checkAuthFlag, "check-auth", false, "Validate the API key with a lightweight call before RPC lookups and batch submissions")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled when NO_COLOR is set or stdout is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", formatText, "Format of the error printed to stderr on failure: text or json")
 
 	// Bind flags to viper
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("api-key", rootCmd.PersistentFlags().Lookup("api-key"))
+	viper.BindPFlag("api-key-file", rootCmd.PersistentFlags().Lookup("api-key-file"))
 	viper.BindPFlag("api-url", rootCmd.PersistentFlags().Lookup("api-url"))
+	viper.BindPFlag("network", rootCmd.PersistentFlags().Lookup("network"))
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
+	viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	viper.BindPFlag("timeout-wait", rootCmd.PersistentFlags().Lookup("timeout-wait"))
+	viper.BindPFlag("user-agent", rootCmd.PersistentFlags().Lookup("user-agent"))
+	viper.BindPFlag("header-override", rootCmd.PersistentFlags().Lookup("header-override"))
+	viper.BindPFlag("rpc-auth-header", rootCmd.PersistentFlags().Lookup("rpc-auth-header"))
+	viper.BindPFlag("rpc-basic-auth", rootCmd.PersistentFlags().Lookup("rpc-basic-auth"))
+	viper.BindPFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	viper.BindPFlag("api-version", rootCmd.PersistentFlags().Lookup("api-version"))
+	viper.BindPFlag("ca-cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindPFlag("insecure-skip-verify", rootCmd.PersistentFlags().Lookup("insecure-skip-verify"))
+	viper.BindPFlag("check-auth", rootCmd.PersistentFlags().Lookup("check-auth"))
+}
+
+// parseHeaderFlags validates the --header values and stores them in headerFlags
+func parseHeaderFlags() error {
+	headerFlags = nil
+	for _, arg := range headerArgs {
+		name, value, err := config.ParseHeader(arg)
+		if err != nil {
+			return err
+		}
+
+		if headerFlags == nil {
+			headerFlags = http.Header{}
+		}
+		headerFlags.Add(name, value)
+	}
+
+	return nil
 }
 
 // initConfig reads in config file and ENV variables if set
@@ -51,25 +195,29 @@ func initConfig() {
 		// Use config file from the flag
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Find home directory
-		home, err := os.UserHomeDir()
+		locations, err := configLocations()
 		if err != nil {
-			fmt.Println(err)
+			logln(err)
 			os.Exit(1)
 		}
 
-		// Search config in home directory with name ".polymer-cli" (without extension)
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".polymer-cli")
+		// Use the first config file found, searching in a fixed order
+		configSearched = locations
+		if path := findConfigFile(locations); path != "" {
+			viper.SetConfigFile(path)
+		}
 	}
 
-	// Read environment variables with prefix POLYMER_
-	viper.SetEnvPrefix("POLYMER")
+	// Read environment variables with prefix POLYMER_, e.g. POLYMER_MAX_ATTEMPTS
+	viper.SetEnvPrefix(config.EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
-
-	// If a config file is found, read it in
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	if err := config.BindEnv(); err != nil {
+		logln(err)
+		os.Exit(1)
 	}
+
+	// If a config file is found, read it in; it is reported once the log
+	// level is known
+	configFileUsed = viper.ReadInConfig() == nil
 }
@@ -2,29 +2,113 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stevenlei/polymer-cli/pkg/api"
 	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/jobs"
 )
 
+var outputFormat string
+var outputJSON bool
+var statusConcurrency int
+var statusLast bool
+var statusFollow bool
+
+// statusOutput is the machine-readable form of a job status
+type statusOutput struct {
+	JobID  string        `json:"jobID" yaml:"jobID"`
+	Status string        `json:"status" yaml:"status"`
+	Proof  proofDocument `json:"proof,omitempty" yaml:"proof,omitempty"`
+	Error  string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
-	Use:   "status [jobID]",
-	Short: "Check the status of a proof generation job",
-	Long: `Check the status of a proof generation job.
+	Use:   "status [jobID...] | --last",
+	Short: "Check the status of proof generation jobs",
+	Long: `Check the status of one or more proof generation jobs.
 
 Provide the job ID that was returned when you requested a proof.
 
+Use --last instead of a job ID to check the most recent job listed by "polymer-cli jobs".
+
+Use --follow to keep polling, like tail -f, until the job completes or fails. Each status
+change is printed to stderr with a timestamp, then the final status and proof are printed as
+without --follow. Polling uses the interval and max-attempts from the config unless
+--interval or --max-attempts is given, and stops on Ctrl-C. A failed job exits non-zero.
+
+Use --export=<file> to also write a completed job to a self-contained JSON artifact with the
+job ID, the request parameters from the job cache, the proof and its checksum. Carry it to
+another machine and check it there with "polymer-cli import" or "polymer-cli verify --artifact".
+
+Use --output=json (or --json) to print a single JSON object with the job ID, status, proof and error,
+or --output=yaml to print the same as YAML. Use --format to print them through a Go template
+instead, e.g. --format '{{.Status}} {{.JobID}}'; the fields are .JobID, .Status, .Proof and .Error.
+
+When several job IDs are given they are checked concurrently and a table of
+job ID and status is printed, or a JSON or YAML list with --output. A failed lookup is
+reported in its row without aborting the others; the command exits non-zero if
+any lookup failed.
+
 Example:
-  polymer-cli status 12345`,
-	Args: cobra.ExactArgs(1),
+  polymer-cli status 12345
+  polymer-cli status 12345 --output=json
+  polymer-cli status --last
+  polymer-cli status 12345 --follow
+  polymer-cli status 12345 12346 12347 --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if statusLast {
+			if len(args) > 0 {
+				return fmt.Errorf("--last cannot be combined with job IDs")
+			}
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Use the most recent job from the job cache if requested
+		if statusLast {
+			store, err := openJobStore()
+			if err != nil {
+				return err
+			}
+			last, err := store.Last()
+			if errors.Is(err, jobs.ErrNoJobs) {
+				return fmt.Errorf("no jobs recorded in %s yet, request a proof first or pass a job ID", store.Path)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get the last job: %w", err)
+			}
+			args = []string{last.JobID}
+		}
+
 		// Get job ID from arguments
 		jobID := args[0]
 
+		if outputJSON {
+			outputFormat = formatJSON
+		}
+		if err := checkOutputFormat(outputFormat); err != nil {
+			return err
+		}
+		if err := parseOutputTemplate(); err != nil {
+			return err
+		}
+		if outputTemplate != nil && outputFormat != formatText {
+			return fmt.Errorf("--format cannot be combined with --output or --json")
+		}
+
 		// Load configuration
 		cfg, err := config.LoadConfig()
 		if err != nil {
@@ -36,82 +120,274 @@ Example:
 			return err
 		}
 
+		if statusFollow {
+			if err := applyPollFlags(cmd, &cfg); err != nil {
+				return err
+			}
+		} else if cmd.Flags().Changed("max-attempts") || cmd.Flags().Changed("interval") {
+			return fmt.Errorf("--max-attempts and --interval can only be used with --follow")
+		}
+
 		// Create API client
-		client := api.NewClient(cfg.APIKey, cfg.APIURL, cfg.Debug)
+		client := newAPIClient(cfg)
 
-		// Get proof status
-		if cfg.Debug {
-			fmt.Printf("Checking status for job ID: %s...\n", jobID)
+		if len(args) > 1 {
+			if statusFollow {
+				return fmt.Errorf("--follow can only be used with a single job ID")
+			}
+			if outputFile != "" {
+				return fmt.Errorf("--output-file can only be used with a single job ID")
+			}
+			if statusExport != "" {
+				return fmt.Errorf("--export can only be used with a single job ID")
+			}
+			if statusConcurrency <= 0 {
+				return fmt.Errorf("concurrency must be greater than 0")
+			}
+
+			return printStatuses(client, args, statusConcurrency)
+		}
+
+		if statusFollow {
+			status, err := followStatus(client, cfg, jobID)
+			if status == nil {
+				return err
+			}
+
+			// A failed job is still printed before its error is returned
+			if printErr := printJobStatus(cfg, jobID, status); printErr != nil {
+				return printErr
+			}
+			return err
 		}
 
+		// Get proof status
+		debugf("Checking status for job ID: %s...\n", jobID)
+
 		status, err := client.GetProofStatus(jobID)
+		if errors.Is(err, api.ErrJobNotFound) {
+			return api.ErrJobNotFound
+		}
 		if err != nil {
 			return fmt.Errorf("failed to get proof status: %w", err)
 		}
 
-		// In non-debug mode, just output the status
-		if !cfg.Debug {
-			fmt.Println(status.Status)
-
-			// If the proof is ready, also print it
-			if status.Status == "completed" && len(status.Proof) > 0 {
-				// Always use raw output in non-debug mode
-				// Try to unmarshal if it's a JSON string
-				var s string
-				if err := json.Unmarshal(status.Proof, &s); err == nil {
-					// It's a JSON string, so use the unquoted value
-					fmt.Print(s)
-				} else {
-					// It's not a JSON string or there was an error
-					rawStr := string(status.Proof)
-					if len(rawStr) >= 2 && rawStr[0] == '"' && rawStr[len(rawStr)-1] == '"' {
-						rawStr = rawStr[1 : len(rawStr)-1]
-					}
-					fmt.Print(rawStr)
-				}
-			}
+		return printJobStatus(cfg, jobID, status)
+	},
+}
 
-			return nil
+// followStatus polls a job until it completes or fails, printing each status
+// change to stderr. It returns the final status, along with the error for a
+// failed job; on other errors, such as a timeout or Ctrl-C, the status is nil.
+func followStatus(client *api.Client, cfg config.Config, jobID string) (*api.ProofStatusResponse, error) {
+	// Stop polling cleanly on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var last *api.ProofStatusResponse
+	onPoll := func(attempt int, status *api.ProofStatusResponse) {
+		if last == nil || status.Status != last.Status {
+			infof("%s %s\n", time.Now().Format(time.RFC3339), status.Status)
 		}
+		last = status
+	}
 
-		// Print status (debug mode)
-		fmt.Printf("Status: %s\n", status.Status)
+	status, err := client.WaitForProofContextWithCallback(ctx, jobID,
+		cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond, onPoll)
+	switch {
+	case err == nil:
+		return status, nil
+	case errors.Is(err, api.ErrProofFailed):
+		return last, err
+	case errors.Is(err, context.Canceled):
+		return nil, errInterrupted
+	case errors.Is(err, api.ErrJobNotFound):
+		return nil, api.ErrJobNotFound
+	default:
+		return nil, err
+	}
+}
 
-		// If there's an error in the status response
-		if status.Error != "" {
-			fmt.Printf("Error: %s\n", status.Error)
+// printJobStatus prints the status of a single job, exporting it or writing
+// its proof to a file first when requested
+func printJobStatus(cfg config.Config, jobID string, status *api.ProofStatusResponse) error {
+	// Export the job as a self-contained artifact, before --output-file
+	// leaves the proof out
+	proofReady := status.Ready()
+	if statusExport != "" {
+		if !proofReady {
+			return fmt.Errorf("job %s is %s, only a completed proof can be exported", jobID, status.Status)
 		}
+		artifact, err := newJobArtifact(cfg, jobID, status)
+		if err != nil {
+			return err
+		}
+		if err := writeArtifactFile(statusExport, artifact); err != nil {
+			return err
+		}
+		infof("Proof artifact written to %s\n", statusExport)
+	}
+
+	// Write a ready proof to a file instead of stdout if requested
+	if outputFile != "" && proofReady {
+		if err := writeProofFile(outputFile, status.Proof); err != nil {
+			return err
+		}
+		infof("Proof written to %s\n", outputFile)
 
-		// If the proof is ready, print it
-		if status.Status == "completed" && len(status.Proof) > 0 {
-			fmt.Println("Proof is ready!")
+		// The proof is in the file, so leave it out of the output below
+		status.Proof = nil
+	}
 
-			if returnRaw {
-				// Try to unmarshal if it's a JSON string
-				var s string
-				if err := json.Unmarshal(status.Proof, &s); err == nil {
-					// It's a JSON string, so use the unquoted value
-					fmt.Print(s)
-				} else {
-					// It's not a JSON string or there was an error
-					rawStr := string(status.Proof)
-					if len(rawStr) >= 2 && rawStr[0] == '"' && rawStr[len(rawStr)-1] == '"' {
-						rawStr = rawStr[1 : len(rawStr)-1]
-					}
-					fmt.Print(rawStr)
-				}
-			} else {
-				// Format as pretty JSON
-				var prettyJSON bytes.Buffer
-				if err := json.Indent(&prettyJSON, status.Proof, "", "  "); err != nil {
-					return fmt.Errorf("failed to format proof as JSON: %w", err)
+	// Structured output is the same in debug and non-debug mode
+	if outputTemplate != nil || outputFormat != formatText {
+		out := statusOutput{
+			JobID:  jobID,
+			Status: status.Status,
+			Proof:  embeddedProof(status.Proof),
+			Error:  status.Error,
+		}
+
+		if outputTemplate != nil {
+			return writeTemplate(out)
+		}
+		return writeStructured(out, outputFormat)
+	}
+
+	// In non-debug mode, just output the status
+	if !cfg.Debug {
+		fmt.Println(status.Status)
+
+		// If the proof is ready, also print it; always raw in non-debug mode
+		if status.Ready() {
+			out, err := formatProof(status.Proof, false)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+		}
+
+		return nil
+	}
+
+	// Print status (debug mode)
+	logf("Status: %s\n", status.Status)
+
+	// If there's an error in the status response
+	if status.Error != "" {
+		logf("Error: %s\n", status.Error)
+	}
+
+	// If the proof is ready, print it
+	if status.Ready() {
+		logln("Proof is ready!")
+
+		out, err := formatProof(status.Proof, !returnRaw)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	}
+
+	return nil
+}
+
+// printStatuses looks up several jobs concurrently and prints one row, or
+// one JSON array element, per job in the order given
+func printStatuses(client *api.Client, jobIDs []string, concurrency int) error {
+	results := make([]statusOutput, len(jobIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].JobID = jobIDs[i]
+
+				status, err := client.GetProofStatus(jobIDs[i])
+				if err != nil {
+					results[i].Error = err.Error()
+					continue
 				}
-				fmt.Println(prettyJSON.String())
+
+				results[i].Status = status.Status
+				results[i].Proof = embeddedProof(status.Proof)
+				results[i].Error = status.Error
+			}
+		}()
+	}
+
+	for i := range jobIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// A lookup failed when no status came back at all
+	failed := 0
+	for _, r := range results {
+		if r.Status == "" {
+			failed++
+		}
+	}
+
+	if outputTemplate != nil {
+		for _, r := range results {
+			if err := writeTemplate(r); err != nil {
+				return err
 			}
 		}
+	} else if err := writeStatuses(results, outputFormat); err != nil {
+		return err
+	}
 
+	if failed > 0 {
+		return fmt.Errorf("%d of %d status lookups failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// writeStatuses prints a table of job ID, status and error, or the results as
+// a JSON or YAML list for those formats
+func writeStatuses(results []statusOutput, format string) error {
+	if format != formatText {
+		return writeStructured(results, format)
+	}
+
+	color := colorEnabled()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "JOB ID\t%s\tERROR\n", colorCell("STATUS", colorDefault, color))
+	for _, r := range results {
+		status := r.Status
+		if status == "" {
+			status = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.JobID, colorCell(status, statusColor(r.Status), color), r.Error)
+	}
+
+	return w.Flush()
+}
+
+// embeddedProof returns the proof as JSON to embed in structured output. A
+// proof that is a JSON string holding a JSON document is unwrapped so it is
+// embedded as that document rather than as a quoted string.
+func embeddedProof(proof json.RawMessage) proofDocument {
+	if len(proof) == 0 {
 		return nil
-	},
+	}
+
+	var s string
+	if err := json.Unmarshal(proof, &s); err == nil {
+		trimmed := bytes.TrimSpace([]byte(s))
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+			return proofDocument(trimmed)
+		}
+	}
+
+	return proofDocument(proof)
 }
 
 func init() {
@@ -119,4 +395,14 @@ func init() {
 
 	// Optional flags
 	statusCmd.Flags().BoolVar(&returnRaw, "raw", false, "Return raw JSON output")
+	statusCmd.Flags().StringVar(&outputFormat, "output", formatText, "Output format: text, json or yaml")
+	statusCmd.Flags().StringVar(&formatFlag, "format", "", "Go template to print each result with, e.g. '{{.Status}} {{.JobID}}'")
+	statusCmd.Flags().BoolVar(&outputJSON, "json", false, "Shorthand for --output=json")
+	statusCmd.Flags().BoolVar(&statusLast, "last", false, "Check the most recently requested job instead of a given job ID")
+	statusCmd.Flags().IntVar(&statusConcurrency, "concurrency", 4, "Number of jobs to check in parallel when several job IDs are given")
+	statusCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the proof to this file instead of stdout once it is ready")
+	statusCmd.Flags().BoolVar(&statusFollow, "follow", false, "Keep polling until the job completes or fails, printing each status change, then print the proof")
+	statusCmd.Flags().IntVar(&maxAttempts, "max-attempts", 0, "Maximum number of polling attempts with --follow (default: value from config)")
+	statusCmd.Flags().IntVar(&interval, "interval", 0, "Polling interval in milliseconds with --follow (default: value from config)")
+	statusCmd.Flags().StringVar(&statusExport, "export", "", "Write the completed job, its request parameters and proof to this file as a JSON artifact for \"import\" or \"verify --artifact\"")
 }
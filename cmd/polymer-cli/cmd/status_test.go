@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestPrintJobStatusProof(t *testing.T) {
+	tests := []struct {
+		status string
+		proof  string
+		want   string
+	}{
+		{"complete", `"AAAA"`, "complete\nAAAA"},
+		{"completed", `"AAAA"`, "completed\nAAAA"},
+		{"Success", `"AAAA"`, "Success\nAAAA"},
+		{"ready", `"AAAA"`, "ready\nAAAA"},
+		{"complete", "", "complete\n"},
+		{"pending", "", "pending\n"},
+		{"generating", "", "generating\n"},
+		{"failed", "", "failed\n"},
+		{"cancelled", `"AAAA"`, "cancelled\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+" "+tt.proof, func(t *testing.T) {
+			status := &api.ProofStatusResponse{Status: tt.status}
+			if tt.proof != "" {
+				status.Proof = json.RawMessage(tt.proof)
+			}
+
+			var err error
+			got := captureStdout(t, func() {
+				err = printJobStatus(config.Config{}, "42", status)
+			})
+			if err != nil {
+				t.Fatalf("printJobStatus() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("printJobStatus() printed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
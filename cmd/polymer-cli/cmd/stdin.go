@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+var readStdin bool
+
+// stdinParamFlags are the request flags that --stdin replaces
+var stdinParamFlags = []string{
+	"chain-id", "block-number", "block-hash", "tx-index", "log-index",
+	"tx-hash", "event-signature", "log-address", "event-occurrence", "dest-chain-id",
+}
+
+// stdinValue is a request parameter given as either a JSON string or number
+type stdinValue string
+
+// UnmarshalJSON accepts a JSON string or number
+func (v *stdinValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = stdinValue(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("expected a string or number, got %s", data)
+	}
+	*v = stdinValue(n)
+
+	return nil
+}
+
+// stdinRequest is a single proof request read from stdin
+type stdinRequest struct {
+	ChainID         stdinValue `json:"chainId"`
+	BlockNumber     stdinValue `json:"blockNumber"`
+	BlockHash       string     `json:"blockHash"`
+	TxIndex         stdinValue `json:"txIndex"`
+	LogIndex        stdinValue `json:"logIndex"`
+	TxHash          string     `json:"txHash"`
+	EventSignature  string     `json:"eventSignature"`
+	LogAddress      string     `json:"logAddress"`
+	EventOccurrence stdinValue `json:"eventOccurrence"`
+	DestChainID     stdinValue `json:"destChainId"`
+}
+
+// requestFromStdin reads one request object, or an array of them, from stdin
+// and requests a proof for each in turn, validating them like the flags
+func requestFromStdin(cmd *cobra.Command, client *api.Client, cfg config.Config) error {
+	for _, name := range stdinParamFlags {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be combined with --stdin", name)
+		}
+	}
+
+	requests, err := readStdinRequests(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if len(requests) > 1 && outputFile != "" {
+		return fmt.Errorf("--output-file can only be used with a single request on stdin")
+	}
+	if len(requests) > 1 && requestOutputFormat != formatText {
+		return fmt.Errorf("--output=%s can only be used with a single request on stdin", requestOutputFormat)
+	}
+
+	for i, req := range requests {
+		if dryRun && i > 0 {
+			fmt.Println()
+		}
+
+		chainID = string(req.ChainID)
+		blockNumber = string(req.BlockNumber)
+		blockHash = req.BlockHash
+		txIndex = string(req.TxIndex)
+		logIndex = string(req.LogIndex)
+		txHash = req.TxHash
+		eventSignatures = nil
+		if req.EventSignature != "" {
+			eventSignatures = []string{req.EventSignature}
+		}
+		logAddress = req.LogAddress
+		eventOccurrence = string(req.EventOccurrence)
+		destChainID = string(req.DestChainID)
+
+		if err := requestFromFlags(client, cfg); err != nil {
+			if len(requests) > 1 {
+				return fmt.Errorf("request %d: %w", i+1, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readStdinRequests decodes a JSON object or array of objects
+func readStdinRequests(r io.Reader) ([]stdinRequest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no request found on stdin")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var requests []stdinRequest
+	if data[0] == '[' {
+		err = decoder.Decode(&requests)
+	} else {
+		var req stdinRequest
+		err = decoder.Decode(&req)
+		requests = append(requests, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request from stdin: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("failed to parse request from stdin: unexpected data after the JSON value")
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no request found on stdin")
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+)
+
+// jobSummary counts the outcomes of an operation on several jobs
+type jobSummary struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	TimedOut  int `json:"timedOut"`
+	// Stopped counts jobs that were not waited for because another one
+	// failed first or the command was interrupted
+	Stopped int `json:"stopped"`
+}
+
+// add counts one job by the error it ended with, nil meaning success
+func (s *jobSummary) add(err error) {
+	switch {
+	case err == nil:
+		s.Succeeded++
+	case errors.Is(err, api.ErrWaitTimeout):
+		s.TimedOut++
+	case errors.Is(err, context.Canceled):
+		s.Stopped++
+	default:
+		s.Failed++
+	}
+}
+
+// String formats the summary as e.g. "12 succeeded, 3 failed, 1 timed out",
+// leaving out timeouts and stopped jobs when there are none
+func (s jobSummary) String() string {
+	parts := []string{
+		fmt.Sprintf("%d succeeded", s.Succeeded),
+		fmt.Sprintf("%d failed", s.Failed),
+	}
+	if s.TimedOut > 0 {
+		parts = append(parts, fmt.Sprintf("%d timed out", s.TimedOut))
+	}
+	if s.Stopped > 0 {
+		parts = append(parts, fmt.Sprintf("%d stopped early", s.Stopped))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// logSummary prints the summary line to stderr, as a JSON object if asJSON
+func logSummary(s jobSummary, asJSON bool) {
+	if !asJSON {
+		logln(s.String())
+		return
+	}
+
+	data, _ := json.Marshal(s)
+	logln(string(data))
+}
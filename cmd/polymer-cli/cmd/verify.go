@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/proof"
+)
+
+var proofFile string
+var artifactFile string
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Sanity-check a proof before submitting it",
+	Long: `Sanity-check a proof before submitting it to a destination contract.
+
+The proof is read from --proof-file, or from stdin when no file is given, as
+base64 or 0x-prefixed hex. Its structure is decoded and the embedded fields are
+checked for internal consistency, e.g. that the source chain ID and block
+number are set and the event fits inside the proof.
+
+With --artifact, the proof is taken from a file written by "status --export"
+instead, and is also checked against the artifact's checksum and, when they
+were recorded, the requested chain, block, transaction index and log index.
+
+This is an offline check; it does not verify the Polymer signature or the
+inclusion proof against on-chain state.
+
+Examples:
+  polymer-cli verify --proof-file=proof.txt
+  polymer-cli wait 12345 | polymer-cli verify
+  polymer-cli verify --artifact=proof-12345.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var p *proof.Proof
+		validate := func() error { return p.Validate() }
+		if artifactFile != "" {
+			if proofFile != "" {
+				return errors.New("--artifact cannot be combined with --proof-file")
+			}
+			a, err := readArtifactFile(artifactFile)
+			if err != nil {
+				return err
+			}
+			if p, err = a.Decode(); err != nil {
+				fmt.Println("FAIL")
+				return err
+			}
+			validate = a.Validate
+			fmt.Printf("Job ID:           %s\n", a.JobID)
+		} else {
+			text, err := readProofInput(proofFile)
+			if err != nil {
+				return err
+			}
+			if p, err = proof.Parse(text); err != nil {
+				fmt.Println("FAIL")
+				return err
+			}
+		}
+
+		fmt.Printf("Source chain ID:  %d\n", p.ChainID)
+		fmt.Printf("Block number:     %d\n", p.BlockNumber)
+		fmt.Printf("Receipt index:    %d\n", p.ReceiptIndex)
+		fmt.Printf("Log index:        %d\n", p.LogIndex)
+		fmt.Printf("Polymer height:   %d\n", p.PolymerHeight)
+
+		if err := validate(); err != nil {
+			fmt.Println("FAIL")
+			var joined interface{ Unwrap() []error }
+			if errors.As(err, &joined) {
+				for _, e := range joined.Unwrap() {
+					fmt.Printf("  - %s\n", e)
+				}
+			} else {
+				fmt.Printf("  - %s\n", err)
+			}
+			return errors.New("proof verification failed")
+		}
+
+		fmt.Println("PASS")
+		return nil
+	},
+}
+
+// readProofInput reads a proof from path, or from stdin when path is empty
+func readProofInput(path string) ([]byte, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proof file: %w", err)
+		}
+		return data, nil
+	}
+
+	if isTerminal(os.Stdin) {
+		return nil, errors.New("no proof given, use --proof-file or pipe the proof to stdin")
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof from stdin: %w", err)
+	}
+
+	return data, nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVar(&proofFile, "proof-file", "", "File containing the proof (default: read from stdin)")
+	verifyCmd.Flags().StringVar(&artifactFile, "artifact", "", "Proof artifact written by status --export to verify instead, or - for stdin")
+}
@@ -1,30 +1,65 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stevenlei/polymer-cli/pkg/api"
 	"github.com/stevenlei/polymer-cli/pkg/config"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
 )
 
 var maxAttempts int
 var interval int
+var waitConcurrency int
+var waitJSON bool
+var waitQuiet bool
+
+// applyPollFlags overrides the config's polling limits with --max-attempts
+// and --interval when they were given
+func applyPollFlags(cmd *cobra.Command, cfg *config.Config) error {
+	if cmd.Flags().Changed("max-attempts") {
+		if maxAttempts <= 0 {
+			return fmt.Errorf("--max-attempts must be greater than 0")
+		}
+		cfg.MaxAttempts = maxAttempts
+	}
+	if cmd.Flags().Changed("interval") {
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be greater than 0")
+		}
+		cfg.Interval = interval
+	}
+
+	return nil
+}
 
 // waitCmd represents the wait command
 var waitCmd = &cobra.Command{
-	Use:   "wait [jobID]",
-	Short: "Wait for a proof to be generated",
+	Use:   "wait [jobID...]",
+	Short: "Wait for proofs to be generated",
 	Long: `Wait for a proof to be generated by polling the status of the job.
 
 Provide the job ID that was returned when you requested a proof.
 
+When several job IDs are given they are polled concurrently until all of them
+are complete or any of them fails, and a table of job ID, status and error is
+printed instead of the proofs, or a JSON array including the proofs with
+--json. A count of succeeded, failed and timed out jobs follows on stderr, as a
+JSON object with --json, unless --quiet is given. The command exits non-zero if
+any job did not complete.
+
 Example:
-  polymer-cli wait 12345 --max-attempts=30 --interval=5000`,
-	Args: cobra.ExactArgs(1),
+  polymer-cli wait 12345 --max-attempts=30 --interval=5000
+  polymer-cli wait 12345 12346 12347 --concurrency=8`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get job ID from arguments
 		jobID := args[0]
@@ -41,30 +76,47 @@ Example:
 		}
 
 		// Override config values with command-line flags if provided
-		if cmd.Flags().Changed("max-attempts") {
-			cfg.MaxAttempts = maxAttempts
-		}
-		if cmd.Flags().Changed("interval") {
-			cfg.Interval = interval
+		if err := applyPollFlags(cmd, &cfg); err != nil {
+			return err
 		}
 
 		// Create API client
-		client := api.NewClient(cfg.APIKey, cfg.APIURL, cfg.Debug)
+		client := newAPIClient(cfg)
+
+		if len(args) > 1 {
+			if waitConcurrency <= 0 {
+				return fmt.Errorf("concurrency must be greater than 0")
+			}
+
+			return waitForJobs(client, args, cfg, waitConcurrency)
+		}
 
 		// Wait for proof - only show debug output if debug flag is enabled
-		if cfg.Debug {
-			fmt.Printf("Waiting for proof with job ID: %s (max %d attempts, %dms interval)...\n",
-				jobID, cfg.MaxAttempts, cfg.Interval)
+		debugf("Waiting for proof with job ID: %s (max %d attempts, %dms interval)...\n",
+			jobID, cfg.MaxAttempts, cfg.Interval)
+
+		// Show a live progress line unless debug output already reports each poll
+		var progress *waitProgress
+		if !cfg.Debug && logger.Enabled(logging.LevelInfo) {
+			progress = startWaitProgress(cfg.MaxAttempts)
 		}
 
-		proofStatus, err := client.WaitForProof(jobID, cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond)
+		// Stop polling cleanly on Ctrl-C
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		proofStatus, err := client.WaitForProofContextWithCallback(ctx, jobID,
+			cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond, progress.onPoll)
+		progress.stop()
+		if errors.Is(err, context.Canceled) {
+			logResumeHint(jobID)
+			return errInterrupted
+		}
 		if err != nil {
 			return fmt.Errorf("failed while waiting for proof: %w", err)
 		}
 
-		if cfg.Debug {
-			fmt.Println("Proof generated successfully!")
-		}
+		debugf("Proof generated successfully!\n")
 
 		// Get the raw flag
 		returnRaw, err := cmd.Flags().GetBool("raw")
@@ -73,33 +125,115 @@ Example:
 		}
 
 		// Output proof - always use raw in non-debug mode
-		if !cfg.Debug || returnRaw {
-			// Try to unmarshal if it's a JSON string
-			var s string
-			if err := json.Unmarshal(proofStatus.Proof, &s); err == nil {
-				// It's a JSON string, so use the unquoted value
-				fmt.Print(s)
-			} else {
-				// It's not a JSON string or there was an error
-				rawStr := string(proofStatus.Proof)
-				if len(rawStr) >= 2 && rawStr[0] == '"' && rawStr[len(rawStr)-1] == '"' {
-					rawStr = rawStr[1 : len(rawStr)-1]
-				}
-				fmt.Print(rawStr)
-			}
-		} else {
-			// Format as pretty JSON (only in debug mode and returnRaw is false)
-			var prettyJSON bytes.Buffer
-			if err := json.Indent(&prettyJSON, proofStatus.Proof, "", "  "); err != nil {
-				return fmt.Errorf("failed to format proof as JSON: %w", err)
-			}
-			fmt.Println(prettyJSON.String())
+		out, err := formatProof(proofStatus.Proof, cfg.Debug && !returnRaw)
+		if err != nil {
+			return err
 		}
+		fmt.Print(out)
 
 		return nil
 	},
 }
 
+// logResumeHint tells the user how to check on a job after they stopped
+// waiting for it; the job itself keeps running on the API
+func logResumeHint(jobID string) {
+	infof("Stopped waiting for job %s, which is still being processed. Check on it later with:\n  polymer-cli status %s\n", jobID, jobID)
+}
+
+// waitForJobs polls several jobs concurrently with a bounded pool of workers
+// sharing one client. The first job that does not complete stops the others.
+func waitForJobs(client *api.Client, jobIDs []string, cfg config.Config, concurrency int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	debugf("Waiting for %d jobs with concurrency %d (max %d attempts, %dms interval)...\n",
+		len(jobIDs), concurrency, cfg.MaxAttempts, cfg.Interval)
+
+	results := make([]statusOutput, len(jobIDs))
+	errs := make([]error, len(jobIDs))
+	var firstErr error
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].JobID = jobIDs[i]
+
+				// Keep the last status seen so jobs that are stopped early still show it
+				onPoll := func(attempt int, status *api.ProofStatusResponse) {
+					results[i].Status = status.Status
+					results[i].Error = status.Error
+				}
+
+				status, err := client.WaitForProofContextWithCallback(ctx, jobIDs[i],
+					cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond, onPoll)
+				if err != nil {
+					errs[i] = err
+					results[i].Error = err.Error()
+
+					mu.Lock()
+					if firstErr == nil && !errors.Is(err, context.Canceled) {
+						firstErr = fmt.Errorf("job %s: %w", jobIDs[i], err)
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+
+				results[i].Status = status.Status
+				results[i].Proof = embeddedProof(status.Proof)
+			}
+		}()
+	}
+
+	// Stop handing out jobs once one has failed
+	for i := range jobIDs {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			results[i].JobID = jobIDs[i]
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var summary jobSummary
+	for i := range results {
+		summary.add(errs[i])
+		if errors.Is(errs[i], context.Canceled) {
+			results[i].Error = "not waited for: stopped early"
+		}
+	}
+
+	format := formatText
+	if waitJSON {
+		format = formatJSON
+	}
+	if err := writeStatuses(results, format); err != nil {
+		return err
+	}
+	if !waitQuiet {
+		logSummary(summary, waitJSON)
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(waitCmd)
 
@@ -107,4 +241,7 @@ func init() {
 	waitCmd.Flags().IntVar(&maxAttempts, "max-attempts", 0, "Maximum number of polling attempts (default: value from config)")
 	waitCmd.Flags().IntVar(&interval, "interval", 0, "Polling interval in milliseconds (default: value from config)")
 	waitCmd.Flags().Bool("raw", false, "Return raw JSON output")
+	waitCmd.Flags().IntVar(&waitConcurrency, "concurrency", 4, "Number of jobs to poll in parallel when several job IDs are given")
+	waitCmd.Flags().BoolVar(&waitJSON, "json", false, "Print the results as a JSON array when several job IDs are given")
+	waitCmd.Flags().BoolVar(&waitQuiet, "quiet", false, "Do not print the summary line to stderr when several job IDs are given")
 }
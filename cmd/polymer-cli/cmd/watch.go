@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/config"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [jobID]",
+	Short: "Print each status change of a proof generation job",
+	Long: `Watch a proof generation job and print a timestamped line each time its status
+changes, until it completes, fails or the polling limit is reached. The limit
+comes from the config unless --max-attempts or --interval is given.
+
+Exits 0 when the proof is complete and non-zero on failure, timeout or Ctrl-C.
+
+Example:
+  polymer-cli watch 12345
+  polymer-cli watch 12345 --max-attempts=100 --interval=10000`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get job ID from arguments
+		jobID := args[0]
+
+		// Load configuration
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// Validate configuration
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		// Override config values with command-line flags if provided
+		if err := applyPollFlags(cmd, &cfg); err != nil {
+			return err
+		}
+
+		// Create API client
+		client := newAPIClient(cfg)
+
+		// Stop polling cleanly on Ctrl-C
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		lastStatus := ""
+		onPoll := func(attempt int, status *api.ProofStatusResponse) {
+			if status.Status == lastStatus {
+				return
+			}
+			lastStatus = status.Status
+			fmt.Printf("%s %s\n", time.Now().Format(time.RFC3339), status.Status)
+		}
+
+		_, err = client.WaitForProofContextWithCallback(ctx, jobID,
+			cfg.MaxAttempts, time.Duration(cfg.Interval)*time.Millisecond, onPoll)
+		if errors.Is(err, context.Canceled) {
+			return errInterrupted
+		}
+
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().IntVar(&maxAttempts, "max-attempts", 0, "Maximum number of polling attempts (default: value from config)")
+	watchCmd.Flags().IntVar(&interval, "interval", 0, "Polling interval in milliseconds (default: value from config)")
+}
@@ -1,15 +1,14 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/stevenlei/polymer-cli/cmd/polymer-cli/cmd"
 )
 
 func main() {
+	// Execute prints the error itself, in the format chosen with --error-format
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// methodNotFoundCode is the JSON-RPC error code for an unknown method
+const methodNotFoundCode = -32601
+
+// ChainInfo describes a source chain the API can prove logs from
+type ChainInfo struct {
+	ChainID uint64 `json:"chainId"`
+	Name    string `json:"name"`
+	Status  string `json:"status,omitempty"`
+}
+
+// KnownChains is a bundled list of source chains for backends that do not
+// implement log_supportedChains. It is not updated at runtime and may be stale.
+var KnownChains = []ChainInfo{
+	{ChainID: 11155111, Name: "Ethereum Sepolia"},
+	{ChainID: 11155420, Name: "Optimism Sepolia"},
+	{ChainID: 84532, Name: "Base Sepolia"},
+	{ChainID: 421614, Name: "Arbitrum Sepolia"},
+	{ChainID: 919, Name: "Mode Sepolia"},
+	{ChainID: 763373, Name: "Ink Sepolia"},
+	{ChainID: 1301, Name: "Unichain Sepolia"},
+}
+
+// GetSupportedChains lists the source chains the API supports. It returns
+// ErrMethodNotSupported when the backend does not implement the call.
+func (c *Client) GetSupportedChains() ([]ChainInfo, error) {
+	return c.GetSupportedChainsContext(context.Background())
+}
+
+// GetSupportedChainsContext is like GetSupportedChains but uses ctx for the HTTP request
+func (c *Client) GetSupportedChainsContext(ctx context.Context) ([]ChainInfo, error) {
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID(),
+		Method:  "log_supportedChains",
+		Params:  []interface{}{},
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	// Send request
+	body, err := c.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON-RPC response, keeping the result raw to decode it into the chain list
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := checkResponseID(request.ID, response.ID, response.Error); err != nil {
+		return nil, err
+	}
+
+	if response.Error != nil {
+		if isMethodNotFound(response.Error) {
+			return nil, fmt.Errorf("%w: log_supportedChains", ErrMethodNotSupported)
+		}
+		return nil, newRPCError(body, response.Error)
+	}
+
+	var chains []ChainInfo
+	if err := json.Unmarshal(response.Result, &chains); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal supported chains: %w", err)
+	}
+
+	return chains, nil
+}
+
+// isMethodNotFound reports whether a JSON-RPC error means the backend does
+// not implement the method
+func isMethodNotFound(rpcErr *JSONRPCError) bool {
+	if rpcErr.Code == methodNotFoundCode {
+		return true
+	}
+
+	msg := strings.ToLower(rpcErr.Message)
+	return strings.Contains(msg, "method not found") || strings.Contains(msg, "does not exist/is not available")
+}
@@ -2,20 +2,138 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/compress"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+	"github.com/stevenlei/polymer-cli/pkg/redact"
+	"github.com/stevenlei/polymer-cli/pkg/timing"
+	"github.com/stevenlei/polymer-cli/pkg/tracelog"
+)
+
+// Default JSON-RPC method names of the Polymer proof API
+const (
+	DefaultRequestMethod = "log_requestProof"
+	DefaultQueryMethod   = "log_queryProof"
 )
 
+// DefaultMaxResponseBytes is the largest response body read when
+// MaxResponseBytes is not set
+const DefaultMaxResponseBytes = 64 << 20
+
 // Client represents a Polymer API client
 type Client struct {
 	APIKey     string
 	APIBaseURL string
 	HTTPClient *http.Client
 	Debug      bool
+	// DebugOutput receives debug messages; it defaults to stderr so that
+	// stdout stays clean for command output
+	DebugOutput io.Writer
+	// Logger, when set, receives all diagnostic messages at its own level
+	// and replaces Debug and DebugOutput
+	Logger *logging.Logger
+
+	// RetryMax is the number of times a request is retried after a network
+	// error or 5xx response. Zero disables retries.
+	RetryMax int
+	// RetryBaseDelay is the delay before the first retry; it doubles on each
+	// subsequent retry.
+	RetryBaseDelay time.Duration
+
+	// IntervalJitter randomizes each polling sleep within plus or minus this
+	// percentage of the interval, so parallel waits drift apart. Zero disables
+	// jitter.
+	IntervalJitter int
+	// Rand is the source used for jitter. When nil the shared math/rand source
+	// is used; set a seeded source for reproducible sleeps.
+	Rand *rand.Rand
+
+	// WaitTimeout caps the total time WaitForProof spends polling. Polling
+	// stops at this deadline or after maxAttempts, whichever comes first.
+	// Reaching the deadline returns an error matching both ErrWaitTimeout
+	// and context.DeadlineExceeded. Zero means no deadline.
+	WaitTimeout time.Duration
+
+	// PollErrorTolerance is the number of consecutive polls WaitForProof lets
+	// fail with ErrUnreachable before giving up. A failed poll still uses up
+	// one of the attempts. Any other error ends the wait at once.
+	PollErrorTolerance int
+
+	// PollBackoff doubles the polling interval after every poll, up to
+	// PollMaxInterval when that is set, instead of polling at a fixed rate
+	PollBackoff     bool
+	PollMaxInterval time.Duration
+
+	// TraceLog, when set, records every HTTP request and response as a JSON
+	// line, regardless of Debug
+	TraceLog *tracelog.Logger
+
+	// Timing, when set, reports the duration of every HTTP round trip
+	Timing *timing.Recorder
+
+	// UserAgent is sent as the User-Agent header when set
+	UserAgent string
+
+	// Headers are added to every request. They never replace Authorization
+	// or Content-Type unless HeaderOverride is set.
+	Headers        http.Header
+	HeaderOverride bool
+
+	// APIVersion, when set, is sent in the X-API-Version header to pin the
+	// request and response format
+	APIVersion     string
+	versionWarning sync.Once
+
+	// OnProofRequested, when set, is called after every successful
+	// RequestProof with the new job ID and the request parameters. It may be
+	// called from several goroutines at once.
+	OnProofRequested func(jobID string, srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint)
+
+	// RequestMethod and QueryMethod override the JSON-RPC methods used to
+	// request a proof and query its status, for compatible services that name
+	// them differently. Empty means DefaultRequestMethod and
+	// DefaultQueryMethod.
+	RequestMethod string
+	QueryMethod   string
+
+	// MaxRetryAfter is the longest Retry-After delay of a 429 response that
+	// is waited out before retrying the request once. A longer delay, a
+	// missing header or a second 429 returns ErrRateLimited instead. Zero
+	// means DefaultMaxRetryAfter; a negative value never retries.
+	MaxRetryAfter time.Duration
+
+	// MaxResponseBytes caps the size of a response body; a larger one fails
+	// with ErrResponseTooLarge instead of being read into memory. Zero means
+	// DefaultMaxResponseBytes.
+	MaxResponseBytes int64
+
+	// DestChainID, when non-zero, is sent as a fifth RequestProof parameter
+	// so the params are [srcChainID, srcBlockNumber, txIndex, logIndex,
+	// destChainID]. Zero keeps the original four-parameter request.
+	DestChainID uint64
+
+	// NextID returns the ID of the next JSON-RPC request. When nil each
+	// client numbers its requests 1, 2, 3, ...; set it for deterministic or
+	// globally unique IDs. It may be called from several goroutines at once.
+	NextID func() int
+	lastID atomic.Int64
+
+	// sleep, when set, replaces sleepContext for every retry and polling
+	// delay, so tests can record the delays instead of waiting them out
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // JSONRPCRequest represents a JSON-RPC request
@@ -48,68 +166,264 @@ type ProofStatusResponse struct {
 }
 
 // NewClient creates a new Polymer API client
-func NewClient(apiKey, apiBaseURL string, debug bool) *Client {
+func NewClient(apiKey, apiBaseURL string, timeout time.Duration, debug bool) *Client {
 	return &Client{
 		APIKey:     apiKey,
 		APIBaseURL: apiBaseURL,
 		HTTPClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: timeout,
 		},
-		Debug: debug,
+		Debug:       debug,
+		DebugOutput: os.Stderr,
 	}
 }
 
-// RequestProof sends a request to generate a proof for a transaction
-func (c *Client) RequestProof(srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint) (string, error) {
-	// Create JSON-RPC request
-	request := JSONRPCRequest{
-		JSONRPC: "2.0",
-		ID:      1,
-		Method:  "log_requestProof",
-		Params:  []interface{}{srcChainID, srcBlockNumber, txIndex, logIndex},
+// logf writes a diagnostic message at level to Logger. Without a Logger,
+// warnings, and debug messages in debug mode, go to DebugOutput.
+func (c *Client) logf(level logging.Level, format string, args ...interface{}) {
+	logger := c.Logger
+	if logger == nil {
+		out := c.DebugOutput
+		if out == nil {
+			out = os.Stderr
+		}
+		maxLevel := logging.LevelWarn
+		if c.Debug {
+			maxLevel = logging.LevelDebug
+		}
+		logger = logging.New(out, maxLevel)
+	}
+	if !logger.Enabled(level) {
+		return
 	}
 
-	reqBody, err := json.Marshal(request)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+	// Never let the full API key reach the log, whatever is being printed
+	msg := fmt.Sprintf(format, args...)
+	if c.APIKey != "" {
+		msg = strings.ReplaceAll(msg, c.APIKey, RedactAPIKey(c.APIKey))
+	}
+	logger.Logf(level, "%s", msg)
+}
+
+// debugf writes a debug message
+func (c *Client) debugf(format string, args ...interface{}) {
+	c.logf(logging.LevelDebug, format, args...)
+}
+
+// tracef writes a message too detailed for debug mode
+func (c *Client) tracef(format string, args ...interface{}) {
+	c.logf(logging.LevelTrace, format, args...)
+}
+
+// nextID returns the ID for a new JSON-RPC request
+func (c *Client) nextID() int {
+	if c.NextID != nil {
+		return c.NextID()
+	}
+	return int(c.lastID.Add(1))
+}
+
+// checkResponseID returns ErrResponseIDMismatch when a response does not
+// answer the request with the given ID. An error response with a null ID is
+// let through, since servers send one when they could not read the request.
+func checkResponseID(requestID, responseID int, rpcErr *JSONRPCError) error {
+	if responseID == requestID || (responseID == 0 && rpcErr != nil) {
+		return nil
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Sending request to %s\n", c.APIBaseURL)
-		fmt.Printf("DEBUG: Request body: %s\n", string(reqBody))
+	return fmt.Errorf("%w: got %d, expected %d", ErrResponseIDMismatch, responseID, requestID)
+}
+
+// post sends a JSON-RPC request body to the API and returns the response body,
+// retrying network errors and 5xx responses with exponential backoff, and a
+// 429 response once after its Retry-After delay
+func (c *Client) post(ctx context.Context, reqBody []byte) ([]byte, error) {
+	var lastErr error
+	rateLimited := false
+	for attempt := 0; attempt <= c.RetryMax; attempt++ {
+		if attempt > 0 {
+			delay := c.RetryBaseDelay * time.Duration(1<<(attempt-1))
+			c.debugf("Retry %d/%d in %s after error: %v\n", attempt, c.RetryMax, delay, lastErr)
+
+			if err := c.pause(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		body, retryable, err := c.send(ctx, reqBody)
+		if delay, ok := c.retryAfter(err); ok && !rateLimited {
+			rateLimited = true
+			c.logf(logging.LevelInfo, "Rate limited by the API, retrying in %s\n", delay.Round(time.Second))
+
+			if err := c.pause(ctx, delay); err != nil {
+				return nil, err
+			}
+			body, retryable, err = c.send(ctx, reqBody)
+		}
+		if err == nil {
+			return body, nil
+		}
+		if !retryable || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
 	}
 
+	return nil, fmt.Errorf("%w: %w", ErrUnreachable, lastErr)
+}
+
+// send performs a single HTTP round trip and reports whether a failure is
+// worth retrying
+func (c *Client) send(ctx context.Context, reqBody []byte) ([]byte, bool, error) {
 	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", c.APIBaseURL, bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.APIBaseURL, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, false, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Accept-Encoding", compress.AcceptEncoding)
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.APIVersion != "" {
+		httpReq.Header.Set(APIVersionHeader, c.APIVersion)
+	}
+	for name, values := range c.Headers {
+		if !c.HeaderOverride && (name == "Authorization" || name == "Content-Type") {
+			continue
+		}
+		httpReq.Header[name] = values
+	}
+
+	c.debugf("Request headers: %v\n", redact.Headers(httpReq.Header))
+	c.TraceLog.Request("api", c.APIBaseURL, reqBody)
 
 	// Send request
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		c.TraceLog.Error("api", c.APIBaseURL, time.Since(start), err)
+		c.Timing.Record("api", reqBody, time.Since(start), err)
+		return nil, true, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body, up to the size limit once decompressed
+	reader, err := compress.Body(resp)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		c.TraceLog.Error("api", c.APIBaseURL, time.Since(start), err)
+		c.Timing.Record("api", reqBody, time.Since(start), err)
+		return nil, false, err
 	}
-
-	if c.Debug {
-		fmt.Printf("DEBUG: Response status: %s\n", resp.Status)
-		fmt.Printf("DEBUG: Response body: %s\n", string(body))
+	body, err := readBody(reader, c.maxResponseBytes())
+	if err != nil {
+		c.TraceLog.Error("api", c.APIBaseURL, time.Since(start), err)
+		c.Timing.Record("api", reqBody, time.Since(start), err)
+		// The same endpoint would send the same oversized body again
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, false, err
+		}
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
 	}
+	c.TraceLog.Response("api", c.APIBaseURL, resp.StatusCode, time.Since(start), body)
+	c.Timing.Record("api", reqBody, time.Since(start), nil)
+	c.checkAPIVersion(resp, body)
+
+	c.debugf("Response status: %s\n", resp.Status)
+	c.tracef("Response headers: %v\n", resp.Header)
+	c.debugf("Response body: %s\n", string(body))
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			apiErr.kind = ErrInvalidAPIKey
+		case http.StatusTooManyRequests:
+			apiErr.kind = ErrRateLimited
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+
+		// Only server errors are transient; 4xx means the request itself is wrong
+		return nil, resp.StatusCode >= 500, apiErr
+	}
+
+	return body, false, nil
+}
+
+// maxResponseBytes returns MaxResponseBytes, or its default when unset
+func (c *Client) maxResponseBytes() int64 {
+	if c.MaxResponseBytes > 0 {
+		return c.MaxResponseBytes
+	}
+	return DefaultMaxResponseBytes
+}
+
+// readBody reads r up to limit bytes, failing with ErrResponseTooLarge when
+// there is more
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a longer one
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: response body exceeds %d bytes, see max-response-bytes", ErrResponseTooLarge, limit)
+	}
+
+	return body, nil
+}
+
+// RequestProof sends a request to generate a proof for a transaction
+func (c *Client) RequestProof(srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint) (string, error) {
+	return c.RequestProofContext(context.Background(), srcChainID, srcBlockNumber, txIndex, logIndex)
+}
+
+// RequestProofBody returns the JSON-RPC body RequestProof posts for the given
+// parameters, e.g. to show it without sending it
+func (c *Client) RequestProofBody(srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint) ([]byte, error) {
+	_, reqBody, err := c.requestProofBody(srcChainID, srcBlockNumber, txIndex, logIndex)
+	return reqBody, err
+}
+
+// requestProofBody is like RequestProofBody but also returns the request ID
+func (c *Client) requestProofBody(srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint) (int, []byte, error) {
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID(),
+		Method:  c.requestMethod(),
+		Params:  []interface{}{srcChainID, srcBlockNumber, txIndex, logIndex},
+	}
+	if c.DestChainID != 0 {
+		request.Params = append(request.Params, c.DestChainID)
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return request.ID, reqBody, nil
+}
+
+// RequestProofContext is like RequestProof but uses ctx for the HTTP request
+func (c *Client) RequestProofContext(ctx context.Context, srcChainID uint64, srcBlockNumber uint64, txIndex uint, logIndex uint) (string, error) {
+	requestID, reqBody, err := c.requestProofBody(srcChainID, srcBlockNumber, txIndex, logIndex)
+	if err != nil {
+		return "", err
+	}
+
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	// Send request
+	body, err := c.post(ctx, reqBody)
+	if err != nil {
+		return "", err
 	}
 
 	// Parse JSON-RPC response
@@ -117,28 +431,101 @@ func (c *Client) RequestProof(srcChainID uint64, srcBlockNumber uint64, txIndex
 	if err := json.Unmarshal(body, &response); err != nil {
 		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	if err := checkResponseID(requestID, response.ID, response.Error); err != nil {
+		return "", err
+	}
 
 	// Check for JSON-RPC error
 	if response.Error != nil {
-		return "", fmt.Errorf("API returned error: %s", response.Error.Message)
+		return "", newRPCError(body, response.Error)
+	}
+
+	jobID, err := jobIDFromResult(response.Result)
+	if err != nil {
+		return "", err
+	}
+
+	if c.OnProofRequested != nil {
+		c.OnProofRequested(jobID, srcChainID, srcBlockNumber, txIndex, logIndex)
 	}
 
-	// Get job ID from result
-	var jobID string
-	switch v := response.Result.(type) {
+	return jobID, nil
+}
+
+// jobIDResultKeys are the fields, in order of preference, that hold the job
+// ID when the API returns an object instead of a bare job ID
+var jobIDResultKeys = []string{"jobId", "jobID", "id"}
+
+// jobIDFromResult extracts the job ID from a RequestProof result, which is a
+// string or number, or an object such as {"jobId": "..."} in newer API
+// versions
+func jobIDFromResult(result interface{}) (string, error) {
+	switch v := result.(type) {
 	case string:
-		jobID = v
+		if v != "" {
+			return v, nil
+		}
 	case float64:
-		jobID = fmt.Sprintf("%.0f", v)
-	default:
-		return "", fmt.Errorf("unexpected result type: %T", response.Result)
+		return fmt.Sprintf("%.0f", v), nil
+	case map[string]interface{}:
+		for _, key := range jobIDResultKeys {
+			switch id := v[key].(type) {
+			case string:
+				if id != "" {
+					return id, nil
+				}
+			case float64:
+				return fmt.Sprintf("%.0f", id), nil
+			}
+		}
+	case nil:
+		return "", fmt.Errorf("API returned no job ID: result is null")
 	}
 
-	return jobID, nil
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("unexpected result type: %T", result)
+	}
+	return "", fmt.Errorf("API returned no job ID: unexpected result %s", payload)
+}
+
+// requestMethod returns the JSON-RPC method used to request a proof
+func (c *Client) requestMethod() string {
+	if c.RequestMethod != "" {
+		return c.RequestMethod
+	}
+	return DefaultRequestMethod
+}
+
+// queryMethod returns the JSON-RPC method used to query a proof job
+func (c *Client) queryMethod() string {
+	if c.QueryMethod != "" {
+		return c.QueryMethod
+	}
+	return DefaultQueryMethod
+}
+
+// backoff returns the polling interval that follows d with PollBackoff set.
+// An interval already at or above the ceiling is kept as is.
+func (c *Client) backoff(d time.Duration) time.Duration {
+	if c.PollMaxInterval > 0 && d >= c.PollMaxInterval {
+		return d
+	}
+
+	d *= 2
+	if c.PollMaxInterval > 0 && d > c.PollMaxInterval {
+		d = c.PollMaxInterval
+	}
+	return d
 }
 
 // GetProofStatus checks the status of a proof generation job
 func (c *Client) GetProofStatus(jobID string) (*ProofStatusResponse, error) {
+	return c.GetProofStatusContext(context.Background(), jobID)
+}
+
+// GetProofStatusContext is like GetProofStatus but uses ctx for the HTTP request
+func (c *Client) GetProofStatusContext(ctx context.Context, jobID string) (*ProofStatusResponse, error) {
 	// Convert job ID to numeric format
 	jobIDNum, err := strconv.ParseFloat(jobID, 64)
 	if err != nil {
@@ -148,8 +535,8 @@ func (c *Client) GetProofStatus(jobID string) (*ProofStatusResponse, error) {
 	// Create JSON-RPC request
 	request := JSONRPCRequest{
 		JSONRPC: "2.0",
-		ID:      1,
-		Method:  "log_queryProof",
+		ID:      c.nextID(),
+		Method:  c.queryMethod(),
 		Params:  []interface{}{jobIDNum},
 	}
 
@@ -158,42 +545,13 @@ func (c *Client) GetProofStatus(jobID string) (*ProofStatusResponse, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Sending request to %s\n", c.APIBaseURL)
-		fmt.Printf("DEBUG: Request body: %s\n", string(reqBody))
-	}
-
-	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", c.APIBaseURL, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
 
 	// Send request
-	resp, err := c.HTTPClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.post(ctx, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if c.Debug {
-		fmt.Printf("DEBUG: Response status: %s\n", resp.Status)
-		fmt.Printf("DEBUG: Response body: %s\n", string(body))
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	// Parse JSON-RPC response
@@ -201,10 +559,20 @@ func (c *Client) GetProofStatus(jobID string) (*ProofStatusResponse, error) {
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	if err := checkResponseID(request.ID, response.ID, response.Error); err != nil {
+		return nil, err
+	}
 
 	// Check for JSON-RPC error
 	if response.Error != nil {
-		return nil, fmt.Errorf("API returned error: %s", response.Error.Message)
+		apiErr := newRPCError(body, response.Error)
+		switch {
+		case isMethodNotFound(response.Error):
+			apiErr.kind = ErrMethodNotSupported
+		case isJobNotFound(response.Error):
+			apiErr.kind = ErrJobNotFound
+		}
+		return nil, apiErr
 	}
 
 	// Parse status response from result
@@ -223,31 +591,118 @@ func (c *Client) GetProofStatus(jobID string) (*ProofStatusResponse, error) {
 
 // WaitForProof polls for a proof until it's generated or max attempts is reached
 func (c *Client) WaitForProof(jobID string, maxAttempts int, interval time.Duration) (*ProofStatusResponse, error) {
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		if c.Debug {
-			fmt.Printf("DEBUG: Polling attempt %d/%d for job %s\n", attempt+1, maxAttempts, jobID)
+	return c.WaitForProofWithCallback(jobID, maxAttempts, interval, nil)
+}
+
+// WaitForProofWithCallback is like WaitForProof but calls onPoll after every
+// successful poll so callers can report progress. onPoll may be nil.
+func (c *Client) WaitForProofWithCallback(jobID string, maxAttempts int, interval time.Duration, onPoll func(attempt int, status *ProofStatusResponse)) (*ProofStatusResponse, error) {
+	return c.WaitForProofContextWithCallback(context.Background(), jobID, maxAttempts, interval, onPoll)
+}
+
+// WaitForProofContext is like WaitForProof but stops polling as soon as ctx is done
+func (c *Client) WaitForProofContext(ctx context.Context, jobID string, maxAttempts int, interval time.Duration) (*ProofStatusResponse, error) {
+	return c.WaitForProofContextWithCallback(ctx, jobID, maxAttempts, interval, nil)
+}
+
+// WaitForProofContextWithCallback is like WaitForProofContext but calls onPoll
+// after every successful poll with the 1-based attempt number and the status
+// returned. onPoll may be nil.
+func (c *Client) WaitForProofContextWithCallback(ctx context.Context, jobID string, maxAttempts int, interval time.Duration, onPoll func(attempt int, status *ProofStatusResponse)) (*ProofStatusResponse, error) {
+	parent := ctx
+	if c.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.WaitTimeout)
+		defer cancel()
+	}
+
+	// waitErr reports our own deadline distinctly from the caller's context.
+	// It matches both ErrWaitTimeout and context.DeadlineExceeded.
+	waitErr := func(err error) error {
+		if c.WaitTimeout > 0 && parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%w: wait timeout (%s) reached without completion (%w)", ErrWaitTimeout, c.WaitTimeout, context.DeadlineExceeded)
+		}
+		return err
+	}
+
+	// sleep waits for the next poll, or bails out if the context is cancelled
+	next := interval
+	sleep := func() error {
+		d := c.jitter(next)
+		if c.PollBackoff {
+			next = c.backoff(next)
 		}
 
-		status, err := c.GetProofStatus(jobID)
+		if err := c.pause(ctx, d); err != nil {
+			return waitErr(err)
+		}
+		return nil
+	}
+
+	pollErrors := 0
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		c.debugf("Polling attempt %d/%d for job %s\n", attempt+1, maxAttempts, jobID)
+
+		status, err := c.GetProofStatusContext(ctx, jobID)
 		if err != nil {
-			return nil, err
+			// Only ride out network trouble, and never past the last attempt
+			if !errors.Is(err, ErrUnreachable) || pollErrors >= c.PollErrorTolerance || attempt+1 == maxAttempts || ctx.Err() != nil {
+				return nil, waitErr(err)
+			}
+
+			pollErrors++
+			c.debugf("Poll failed (%d/%d consecutive errors tolerated), retrying: %v\n", pollErrors, c.PollErrorTolerance, err)
+			if err := sleep(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pollErrors = 0
+
+		if onPoll != nil {
+			onPoll(attempt+1, status)
 		}
 
-		switch status.Status {
-		case "complete", "completed":
+		switch status.State() {
+		case ProofStatusComplete:
 			return status, nil
-		case "failed":
-			return nil, fmt.Errorf("proof generation failed: %s", status.Error)
-		case "pending", "processing":
-			// Continue polling
-			if c.Debug {
-				fmt.Printf("DEBUG: Job status: %s, waiting...\n", status.Status)
-			}
-			time.Sleep(interval)
+		case ProofStatusFailed:
+			return nil, fmt.Errorf("%w: %s", ErrProofFailed, status.Error)
 		default:
-			return nil, fmt.Errorf("unknown job status: %s", status.Status)
+			// Continue polling; an unrecognized status is treated as still in progress
+			if status.State() == ProofStatusUnknown {
+				c.debugf("Unrecognized job status %q, continuing to poll\n", status.Status)
+			} else {
+				c.debugf("Job status: %s, waiting...\n", status.Status)
+			}
+
+			if err := sleep(); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return nil, fmt.Errorf("max polling attempts (%d) reached without completion", maxAttempts)
+	return nil, fmt.Errorf("%w: max polling attempts (%d) reached without completion", ErrWaitTimeout, maxAttempts)
+}
+
+// jitter returns interval shifted by a random amount of up to IntervalJitter
+// percent in either direction
+func (c *Client) jitter(interval time.Duration) time.Duration {
+	if c.IntervalJitter <= 0 || interval <= 0 {
+		return interval
+	}
+
+	spread := int64(interval) * int64(c.IntervalJitter) / 100
+	if spread == 0 {
+		return interval
+	}
+
+	var offset int64
+	if c.Rand != nil {
+		offset = c.Rand.Int63n(2*spread+1) - spread
+	} else {
+		offset = rand.Int63n(2*spread+1) - spread
+	}
+
+	return interval + time.Duration(offset)
 }
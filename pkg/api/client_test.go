@@ -0,0 +1,359 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers every status query with the next of statuses,
+// repeating the last one once they run out, and counts the queries
+func statusServer(t *testing.T, statuses ...ProofStatusResponse) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		if request.Method != DefaultQueryMethod {
+			t.Errorf("method = %q, want %q", request.Method, DefaultQueryMethod)
+		}
+
+		n := int(polls.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": status})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &polls
+}
+
+func TestWaitForProofWithCallback(t *testing.T) {
+	server, polls := statusServer(t,
+		ProofStatusResponse{Status: "queued"},
+		ProofStatusResponse{Status: "generating"},
+		ProofStatusResponse{Status: "generating"},
+		ProofStatusResponse{Status: "complete", Proof: json.RawMessage(`"AAAA"`)},
+	)
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	type poll struct {
+		attempt int
+		status  string
+	}
+	var got []poll
+	status, err := client.WaitForProofWithCallback("42", 10, time.Millisecond, func(attempt int, status *ProofStatusResponse) {
+		got = append(got, poll{attempt, status.Status})
+	})
+	if err != nil {
+		t.Fatalf("WaitForProofWithCallback() error: %v", err)
+	}
+	if !status.Ready() {
+		t.Errorf("returned status %+v, want a ready proof", status)
+	}
+
+	want := []poll{{1, "queued"}, {2, "generating"}, {3, "generating"}, {4, "complete"}}
+	if len(got) != len(want) {
+		t.Fatalf("callback fired %d times (%v), want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("poll %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if n := polls.Load(); n != int32(len(want)) {
+		t.Errorf("server saw %d polls, want %d", n, len(want))
+	}
+}
+
+func TestWaitForProofWithCallbackFailed(t *testing.T) {
+	server, _ := statusServer(t,
+		ProofStatusResponse{Status: "pending"},
+		ProofStatusResponse{Status: "failed", Error: "log not found"},
+	)
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	var attempts []int
+	_, err := client.WaitForProofWithCallback("42", 10, time.Millisecond, func(attempt int, status *ProofStatusResponse) {
+		attempts = append(attempts, attempt)
+	})
+	if !errors.Is(err, ErrProofFailed) {
+		t.Errorf("WaitForProofWithCallback() error = %v, want it to match ErrProofFailed", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("callback attempts = %v, want [1 2]", attempts)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	server, polls := statusServer(t, ProofStatusResponse{Status: "pending"})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+	client.WaitTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := client.WaitForProof("42", 1000, 10*time.Millisecond)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForProof() error = %v, want it to match context.DeadlineExceeded", err)
+	}
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitForProof() error = %v, want it to match ErrWaitTimeout", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("WaitForProof() took %s, want it to stop near the 50ms deadline", elapsed)
+	}
+	if n := polls.Load(); n < 2 || n > 10 {
+		t.Errorf("server saw %d polls, want a few within the deadline", n)
+	}
+}
+
+func TestWaitTimeoutMaxAttemptsFirst(t *testing.T) {
+	server, polls := statusServer(t, ProofStatusResponse{Status: "pending"})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+	client.WaitTimeout = time.Minute
+
+	_, err := client.WaitForProof("42", 3, time.Millisecond)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitForProof() error = %v, want it to match ErrWaitTimeout", err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForProof() error = %v, want running out of attempts not to match context.DeadlineExceeded", err)
+	}
+	if n := polls.Load(); n != 3 {
+		t.Errorf("server saw %d polls, want 3", n)
+	}
+}
+
+func TestWaitTimeoutCallerDeadline(t *testing.T) {
+	server, _ := statusServer(t, ProofStatusResponse{Status: "pending"})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+	client.WaitTimeout = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForProofContext(ctx, "42", 1000, 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForProofContext() error = %v, want it to match context.DeadlineExceeded", err)
+	}
+	// The caller's own deadline is not the wait timeout
+	if errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("WaitForProofContext() error = %v, want the caller's deadline not to match ErrWaitTimeout", err)
+	}
+}
+
+// recordSleeps makes client record its delays instead of sleeping
+func recordSleeps(client *Client) *[]time.Duration {
+	var sleeps []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return &sleeps
+}
+
+func TestPollBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		backoff     bool
+		interval    time.Duration
+		maxInterval time.Duration
+		want        []time.Duration
+	}{
+		{
+			name:     "fixed interval",
+			interval: time.Second,
+			want:     []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second},
+		},
+		{
+			name:        "doubles up to the ceiling",
+			backoff:     true,
+			interval:    time.Second,
+			maxInterval: 5 * time.Second,
+			want:        []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "doubles without a ceiling",
+			backoff:  true,
+			interval: time.Second,
+			want:     []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second},
+		},
+		{
+			name:        "interval above the ceiling is kept",
+			backoff:     true,
+			interval:    10 * time.Second,
+			maxInterval: 5 * time.Second,
+			want:        []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Five pending polls, so five sleeps, before the proof is ready
+			statuses := make([]ProofStatusResponse, len(tt.want), len(tt.want)+1)
+			for i := range statuses {
+				statuses[i] = ProofStatusResponse{Status: "pending"}
+			}
+			server, _ := statusServer(t, append(statuses, ProofStatusResponse{Status: "complete", Proof: json.RawMessage(`"AAAA"`)})...)
+
+			client := NewClient("key", server.URL, 5*time.Second, false)
+			client.PollBackoff = tt.backoff
+			client.PollMaxInterval = tt.maxInterval
+			sleeps := recordSleeps(client)
+
+			if _, err := client.WaitForProof("42", 10, tt.interval); err != nil {
+				t.Fatalf("WaitForProof() error: %v", err)
+			}
+			if !reflect.DeepEqual(*sleeps, tt.want) {
+				t.Errorf("sleeps = %v, want %v", *sleeps, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollBackoffJitter(t *testing.T) {
+	run := func(seed int64) []time.Duration {
+		server, _ := statusServer(t, ProofStatusResponse{Status: "pending"})
+		client := NewClient("key", server.URL, 5*time.Second, false)
+		client.PollBackoff = true
+		client.PollMaxInterval = 8 * time.Second
+		client.IntervalJitter = 10
+		client.Rand = rand.New(rand.NewSource(seed))
+		sleeps := recordSleeps(client)
+
+		if _, err := client.WaitForProof("42", 7, time.Second); !errors.Is(err, ErrWaitTimeout) {
+			t.Fatalf("WaitForProof() error = %v, want ErrWaitTimeout", err)
+		}
+		return *sleeps
+	}
+
+	sleeps := run(1)
+	// Every one of the 7 pending polls is followed by a sleep
+	bases := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second}
+	if len(sleeps) != len(bases) {
+		t.Fatalf("slept %d times (%v), want %d", len(sleeps), sleeps, len(bases))
+	}
+	for i, base := range bases {
+		// Jitter applies to the current interval and never exceeds 10%
+		if spread := base / 10; sleeps[i] < base-spread || sleeps[i] > base+spread {
+			t.Errorf("sleep %d = %s, want %s ± 10%%", i, sleeps[i], base)
+		}
+	}
+
+	if again := run(1); !reflect.DeepEqual(again, sleeps) {
+		t.Errorf("sleeps with the same seed = %v, want %v", again, sleeps)
+	}
+}
+
+// replyServer answers every JSON-RPC request with the body reply returns for
+// the request's ID
+func replyServer(t *testing.T, reply func(id int) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		io.WriteString(w, reply(request.ID))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestResponseIDMismatch(t *testing.T) {
+	calls := []struct {
+		name   string
+		result string
+		call   func(c *Client) error
+	}{
+		{"RequestProof", `"42"`, func(c *Client) error {
+			_, err := c.RequestProof(11155420, 24639225, 4, 1)
+			return err
+		}},
+		{"GetProofStatus", `{"jobID":"42","status":"pending"}`, func(c *Client) error {
+			_, err := c.GetProofStatus("42")
+			return err
+		}},
+	}
+
+	for _, call := range calls {
+		t.Run(call.name+" wrong ID", func(t *testing.T) {
+			server := replyServer(t, func(id int) string {
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, id+1, call.result)
+			})
+			err := call.call(NewClient("key", server.URL, 5*time.Second, false))
+			if !errors.Is(err, ErrResponseIDMismatch) {
+				t.Errorf("%s() error = %v, want it to match ErrResponseIDMismatch", call.name, err)
+			}
+		})
+
+		t.Run(call.name+" error with null ID", func(t *testing.T) {
+			server := replyServer(t, func(int) string {
+				return `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`
+			})
+			err := call.call(NewClient("key", server.URL, 5*time.Second, false))
+			if errors.Is(err, ErrResponseIDMismatch) {
+				t.Errorf("%s() error = %v, want the server's error, not an ID mismatch", call.name, err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.RPCError == nil || apiErr.RPCError.Code != -32700 {
+				t.Errorf("%s() error = %#v, want an APIError with code -32700", call.name, err)
+			}
+		})
+	}
+}
+
+func TestNextID(t *testing.T) {
+	var ids []int
+	server := replyServer(t, func(id int) string {
+		ids = append(ids, id)
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"42"}`, id)
+	})
+	request := func(c *Client) {
+		t.Helper()
+		if _, err := c.RequestProof(11155420, 24639225, 4, 1); err != nil {
+			t.Fatalf("RequestProof() error: %v", err)
+		}
+	}
+
+	// An injected source decides the IDs sent
+	injected := NewClient("key", server.URL, 5*time.Second, false)
+	next := 1000
+	injected.NextID = func() int {
+		next++
+		return next
+	}
+	request(injected)
+	request(injected)
+	if want := []int{1001, 1002}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("injected IDs = %v, want %v", ids, want)
+	}
+
+	// Without one, each client counts its own requests from 1
+	ids = nil
+	a := NewClient("key", server.URL, 5*time.Second, false)
+	b := NewClient("key", server.URL, 5*time.Second, false)
+	request(a)
+	request(a)
+	request(b)
+	request(a)
+	if want := []int{1, 2, 1, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs of two clients = %v, want %v", ids, want)
+	}
+}
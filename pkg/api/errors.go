@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned when the API does not know the requested job ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrUnreachable is returned when the API could not be reached, or kept
+	// failing with 5xx responses, after all retries
+	ErrUnreachable = errors.New("API unreachable")
+	// ErrProofFailed is returned when the API reports that proof generation failed
+	ErrProofFailed = errors.New("proof generation failed")
+	// ErrWaitTimeout is returned when waiting for a proof runs out of attempts
+	// or time before the proof is complete
+	ErrWaitTimeout = errors.New("timed out waiting for proof")
+	// ErrMethodNotSupported is returned when the backend does not implement a
+	// JSON-RPC method, e.g. an older deployment
+	ErrMethodNotSupported = errors.New("method not supported by the API")
+	// ErrResponseIDMismatch is returned when a JSON-RPC response carries a
+	// different ID than the request it was sent for, e.g. a stale response
+	// from a misbehaving proxy
+	ErrResponseIDMismatch = errors.New("response ID does not match request ID")
+	// ErrInvalidAPIKey is returned when the API rejects the API key with a 401
+	// or 403 response
+	ErrInvalidAPIKey = errors.New("invalid or expired API key")
+	// ErrResponseTooLarge is returned when a response body is larger than
+	// the client's MaxResponseBytes
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrRateLimited is returned when the API answers 429 Too Many Requests
+	// and the request was not retried; the APIError holds the RetryAfter
+	// delay the API suggested
+	ErrRateLimited = errors.New("rate limited by the API")
+)
+
+// APIError is returned when the API answers with an error: a non-200 HTTP
+// status, or a JSON-RPC error in a 200 response. Use errors.As to branch on
+// the status code; errors.Is still matches ErrInvalidAPIKey for 401 and 403
+// responses, ErrRateLimited for 429 responses and ErrJobNotFound for unknown
+// job IDs.
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Body is the response body
+	Body string
+	// RPCError is the JSON-RPC error of the response, if it carried one
+	RPCError *JSONRPCError
+	// RetryAfter is the delay a 429 response asked for in its Retry-After
+	// header, or zero when it gave none
+	RetryAfter time.Duration
+
+	// kind is the sentinel error the error also matches, if any
+	kind error
+}
+
+func (e *APIError) Error() string {
+	var msg string
+	switch {
+	case e.RPCError != nil && e.kind != nil:
+		msg = e.RPCError.Message
+	case e.RPCError != nil:
+		msg = "API returned error: " + e.RPCError.Message
+	default:
+		msg = fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, strings.TrimSpace(e.Body))
+	}
+
+	if e.kind != nil {
+		return e.kind.Error() + ": " + msg
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error { return e.kind }
+
+// newRPCError returns the APIError for a JSON-RPC error in a 200 response
+func newRPCError(body []byte, rpcErr *JSONRPCError) *APIError {
+	return &APIError{StatusCode: http.StatusOK, Body: string(body), RPCError: rpcErr}
+}
+
+// jobNotFoundPattern matches the messages the API uses for an unknown job ID
+var jobNotFoundPattern = regexp.MustCompile(`\b(job|proof request)\b.*\b(not found|does not exist)|\b(unknown|no such) (job|proof request)\b`)
+
+// isJobNotFound reports whether a JSON-RPC error means the job ID is unknown.
+// The API does not use a dedicated error code for this, so the message is
+// matched instead. An unknown method, e.g. a wrong --query-method, also says
+// "not found" and is never taken for an unknown job.
+func isJobNotFound(rpcErr *JSONRPCError) bool {
+	if isMethodNotFound(rpcErr) {
+		return false
+	}
+
+	return jobNotFoundPattern.MatchString(strings.ToLower(rpcErr.Message))
+}
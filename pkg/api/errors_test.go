@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsJobNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  JSONRPCError
+		want bool
+	}{
+		{"job not found", JSONRPCError{Code: -32000, Message: "job not found"}, true},
+		{"job id not found", JSONRPCError{Code: -32000, Message: "Job ID 42 not found"}, true},
+		{"job does not exist", JSONRPCError{Code: -32000, Message: "job 42 does not exist"}, true},
+		{"unknown job", JSONRPCError{Code: -32602, Message: "unknown job: 42"}, true},
+		{"no such job", JSONRPCError{Code: -32000, Message: "no such job"}, true},
+		{"proof request not found", JSONRPCError{Code: -32000, Message: "proof request 42 not found"}, true},
+		{"method not found code", JSONRPCError{Code: -32601, Message: "the method log_queryProofs does not exist/is not available"}, false},
+		{"method not found message", JSONRPCError{Code: -32000, Message: "Method not found"}, false},
+		{"method not found code with job wording", JSONRPCError{Code: -32601, Message: "job method not found"}, false},
+		{"other not found", JSONRPCError{Code: -32000, Message: "block not found"}, false},
+		{"unrelated", JSONRPCError{Code: -32000, Message: "internal error"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJobNotFound(&tt.err); got != tt.want {
+				t.Errorf("isJobNotFound(%+v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// rpcErrorServer answers every JSON-RPC request with rpcErr, echoing its ID
+func rpcErrorServer(t *testing.T, rpcErr JSONRPCError) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "error": rpcErr})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGetProofStatusErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		rpcErr  JSONRPCError
+		want    error
+		notWant error
+	}{
+		{"unknown job", JSONRPCError{Code: -32000, Message: "job not found"}, ErrJobNotFound, ErrMethodNotSupported},
+		{"unknown method", JSONRPCError{Code: -32601, Message: "method not found"}, ErrMethodNotSupported, ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rpcErrorServer(t, tt.rpcErr)
+			client := NewClient("key", server.URL, 5*time.Second, false)
+
+			_, err := client.GetProofStatus("42")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("GetProofStatus() error = %v, want it to match %v", err, tt.want)
+			}
+			if errors.Is(err, tt.notWant) {
+				t.Errorf("GetProofStatus() error = %v, want it not to match %v", err, tt.notWant)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.RPCError == nil || apiErr.RPCError.Code != tt.rpcErr.Code {
+				t.Errorf("GetProofStatus() error = %#v, want an APIError with code %d", err, tt.rpcErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// listJobsMethod is the JSON-RPC method that lists the jobs of an API key
+const listJobsMethod = "log_listJobs"
+
+// JobSummary describes a proof job as listed by ListJobs
+type JobSummary struct {
+	JobID          json.Number `json:"jobID"`
+	Status         string      `json:"status"`
+	SrcChainID     uint64      `json:"srcChainId,omitempty"`
+	SrcBlockNumber uint64      `json:"srcBlockNumber,omitempty"`
+	TxIndex        uint        `json:"txIndex"`
+	LogIndex       uint        `json:"logIndex"`
+	CreatedAt      string      `json:"createdAt,omitempty"`
+}
+
+// JobPage is one page of jobs returned by ListJobs
+type JobPage struct {
+	Jobs []JobSummary `json:"jobs"`
+	// Total is the number of jobs across all pages, or zero when the
+	// backend does not report it
+	Total int `json:"total,omitempty"`
+	// More reports whether jobs remain after this page
+	More bool `json:"more"`
+}
+
+// ListJobs returns up to limit jobs, most recent first, after skipping the
+// offset most recent ones. It returns ErrMethodNotSupported when the backend
+// does not implement the call.
+func (c *Client) ListJobs(limit, offset int) (*JobPage, error) {
+	return c.ListJobsContext(context.Background(), limit, offset)
+}
+
+// ListJobsContext is like ListJobs but uses ctx for the HTTP request
+func (c *Client) ListJobsContext(ctx context.Context, limit, offset int) (*JobPage, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid page size %d: must be greater than 0", limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d: must not be negative", offset)
+	}
+
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID(),
+		Method:  listJobsMethod,
+		Params:  []interface{}{map[string]int{"limit": limit, "offset": offset}},
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	// Send request
+	body, err := c.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON-RPC response, keeping the result raw to decode it into the page
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := checkResponseID(request.ID, response.ID, response.Error); err != nil {
+		return nil, err
+	}
+
+	if response.Error != nil {
+		if isMethodNotFound(response.Error) {
+			return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, listJobsMethod)
+		}
+		return nil, newRPCError(body, response.Error)
+	}
+
+	var result struct {
+		Jobs    []JobSummary `json:"jobs"`
+		Total   int          `json:"total"`
+		HasMore *bool        `json:"hasMore"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job list: %w", err)
+	}
+	if len(result.Jobs) > limit {
+		return nil, fmt.Errorf("job list has %d jobs, more than the requested %d", len(result.Jobs), limit)
+	}
+
+	page := &JobPage{Jobs: result.Jobs, Total: result.Total}
+	switch {
+	case result.HasMore != nil:
+		page.More = *result.HasMore
+	case result.Total > 0:
+		page.More = offset+len(result.Jobs) < result.Total
+	default:
+		// Without either hint, a full page may be followed by another
+		page.More = len(result.Jobs) == limit
+	}
+
+	return page, nil
+}
+
+// EachJob calls fn with every job, most recent first, fetching pages of
+// pageSize jobs as they are needed so that only one page is held at a time.
+// It stops at the first error returned by fn or by the API.
+func (c *Client) EachJob(ctx context.Context, pageSize int, fn func(JobSummary) error) error {
+	for offset := 0; ; {
+		page, err := c.ListJobsContext(ctx, pageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list jobs at offset %d: %w", offset, err)
+		}
+
+		for _, job := range page.Jobs {
+			if err := fn(job); err != nil {
+				return err
+			}
+		}
+
+		// An empty page ends the listing even if the backend claims more,
+		// so a misbehaving backend cannot loop forever
+		if !page.More || len(page.Jobs) == 0 {
+			return nil
+		}
+		offset += len(page.Jobs)
+	}
+}
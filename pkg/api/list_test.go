@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// jobListServer serves log_listJobs from a list of total jobs, numbered from
+// total down to 1, recording the offset of each call
+func jobListServer(t *testing.T, total int, reply func(jobs []JobSummary) map[string]interface{}) (*httptest.Server, *[]int) {
+	t.Helper()
+
+	var offsets []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+			Params []struct {
+				Limit  int `json:"limit"`
+				Offset int `json:"offset"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || len(request.Params) != 1 {
+			t.Errorf("invalid request body %q: %v", body, err)
+			return
+		}
+		if request.Method != listJobsMethod {
+			t.Errorf("method = %q, want %q", request.Method, listJobsMethod)
+		}
+
+		limit, offset := request.Params[0].Limit, request.Params[0].Offset
+		offsets = append(offsets, offset)
+
+		var jobs []JobSummary
+		for i := offset; i < total && len(jobs) < limit; i++ {
+			jobs = append(jobs, JobSummary{JobID: json.Number(fmt.Sprint(total - i)), Status: "complete"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": reply(jobs)})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &offsets
+}
+
+func TestListJobsMore(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		reply  func(jobs []JobSummary) map[string]interface{}
+		total  int
+		want   bool
+	}{
+		{
+			name: "hasMore true",
+			reply: func(jobs []JobSummary) map[string]interface{} {
+				return map[string]interface{}{"jobs": jobs, "hasMore": true}
+			},
+			want: true,
+		},
+		{
+			name: "hasMore false on a full page",
+			reply: func(jobs []JobSummary) map[string]interface{} {
+				return map[string]interface{}{"jobs": jobs, "hasMore": false}
+			},
+			want: false,
+		},
+		{
+			name: "total beyond page",
+			reply: func(jobs []JobSummary) map[string]interface{} {
+				return map[string]interface{}{"jobs": jobs, "total": 7}
+			},
+			total: 7,
+			want:  true,
+		},
+		{
+			name:   "total reached",
+			offset: 4,
+			reply: func(jobs []JobSummary) map[string]interface{} {
+				return map[string]interface{}{"jobs": jobs, "total": 7}
+			},
+			total: 7,
+			want:  false,
+		},
+		{
+			name:  "full page without hints",
+			reply: func(jobs []JobSummary) map[string]interface{} { return map[string]interface{}{"jobs": jobs} },
+			want:  true,
+		},
+		{
+			name:   "short page without hints",
+			offset: 4,
+			reply:  func(jobs []JobSummary) map[string]interface{} { return map[string]interface{}{"jobs": jobs} },
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := jobListServer(t, 7, tt.reply)
+			client := NewClient("key", server.URL, 5*time.Second, false)
+
+			page, err := client.ListJobs(4, tt.offset)
+			if err != nil {
+				t.Fatalf("ListJobs() error: %v", err)
+			}
+			if page.More != tt.want {
+				t.Errorf("More = %v, want %v", page.More, tt.want)
+			}
+			if page.Total != tt.total {
+				t.Errorf("Total = %d, want %d", page.Total, tt.total)
+			}
+			if want := min(4, 7-tt.offset); len(page.Jobs) != want {
+				t.Errorf("got %d jobs, want %d", len(page.Jobs), want)
+			}
+		})
+	}
+}
+
+func TestListJobsInvalidArguments(t *testing.T) {
+	client := NewClient("key", "http://127.0.0.1:0", time.Second, false)
+	for _, args := range [][2]int{{0, 0}, {-1, 0}, {10, -1}} {
+		if _, err := client.ListJobs(args[0], args[1]); err == nil {
+			t.Errorf("ListJobs(%d, %d) succeeded, want an error", args[0], args[1])
+		}
+	}
+}
+
+func TestListJobsMethodNotSupported(t *testing.T) {
+	server := rpcErrorServer(t, JSONRPCError{Code: -32601, Message: "method not found"})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	if _, err := client.ListJobs(10, 0); !errors.Is(err, ErrMethodNotSupported) {
+		t.Errorf("ListJobs() error = %v, want it to match ErrMethodNotSupported", err)
+	}
+}
+
+func TestEachJob(t *testing.T) {
+	server, offsets := jobListServer(t, 7, func(jobs []JobSummary) map[string]interface{} {
+		return map[string]interface{}{"jobs": jobs}
+	})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	var ids []string
+	err := client.EachJob(context.Background(), 3, func(job JobSummary) error {
+		ids = append(ids, job.JobID.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachJob() error: %v", err)
+	}
+
+	if fmt.Sprint(ids) != "[7 6 5 4 3 2 1]" {
+		t.Errorf("jobs = %v, want 7 down to 1", ids)
+	}
+	if fmt.Sprint(*offsets) != "[0 3 6]" {
+		t.Errorf("offsets = %v, want [0 3 6]", *offsets)
+	}
+}
+
+func TestEachJobStopsOnExactPage(t *testing.T) {
+	// Six jobs in pages of three: the third call returns an empty page
+	server, offsets := jobListServer(t, 6, func(jobs []JobSummary) map[string]interface{} {
+		return map[string]interface{}{"jobs": jobs, "hasMore": true}
+	})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	count := 0
+	if err := client.EachJob(context.Background(), 3, func(JobSummary) error { count++; return nil }); err != nil {
+		t.Fatalf("EachJob() error: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("got %d jobs, want 6", count)
+	}
+	if fmt.Sprint(*offsets) != "[0 3 6]" {
+		t.Errorf("offsets = %v, want [0 3 6]", *offsets)
+	}
+}
+
+func TestEachJobCallbackError(t *testing.T) {
+	server, offsets := jobListServer(t, 7, func(jobs []JobSummary) map[string]interface{} {
+		return map[string]interface{}{"jobs": jobs}
+	})
+	client := NewClient("key", server.URL, 5*time.Second, false)
+
+	stop := errors.New("stop")
+	err := client.EachJob(context.Background(), 3, func(JobSummary) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("EachJob() error = %v, want %v", err, stop)
+	}
+	if len(*offsets) != 1 {
+		t.Errorf("fetched %d pages, want 1", len(*offsets))
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RequestProofMultiMethod is the JSON-RPC method that requests proofs for
+// several logs of one transaction in a single call
+const RequestProofMultiMethod = "log_requestProofs"
+
+// RequestProofMulti requests proofs for several logs of the same transaction
+// and returns one job ID per log index, in order. It uses the bulk
+// log_requestProofs method and falls back to one RequestProof call per log
+// when the backend does not implement it.
+func (c *Client) RequestProofMulti(srcChainID, srcBlockNumber, txIndex uint64, logIndices []uint) ([]string, error) {
+	return c.RequestProofMultiContext(context.Background(), srcChainID, srcBlockNumber, txIndex, logIndices)
+}
+
+// RequestProofMultiContext is like RequestProofMulti but uses ctx for the
+// HTTP requests. When the sequential fallback fails part way, the job IDs
+// requested so far are returned along with the error.
+func (c *Client) RequestProofMultiContext(ctx context.Context, srcChainID, srcBlockNumber, txIndex uint64, logIndices []uint) ([]string, error) {
+	if len(logIndices) == 0 {
+		return nil, errors.New("no log indices given")
+	}
+
+	jobIDs, err := c.requestProofBulk(ctx, srcChainID, srcBlockNumber, txIndex, logIndices)
+	if !errors.Is(err, ErrMethodNotSupported) {
+		return jobIDs, err
+	}
+
+	c.debugf("%s is not supported, requesting %d proofs one at a time\n", RequestProofMultiMethod, len(logIndices))
+
+	jobIDs = make([]string, 0, len(logIndices))
+	for _, logIdx := range logIndices {
+		jobID, err := c.RequestProofContext(ctx, srcChainID, srcBlockNumber, uint(txIndex), logIdx)
+		if err != nil {
+			return jobIDs, fmt.Errorf("failed to request proof for log %d: %w", logIdx, err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, nil
+}
+
+// requestProofBulk sends a single log_requestProofs call. It returns
+// ErrMethodNotSupported when the backend does not implement the method.
+func (c *Client) requestProofBulk(ctx context.Context, srcChainID, srcBlockNumber, txIndex uint64, logIndices []uint) ([]string, error) {
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID(),
+		Method:  RequestProofMultiMethod,
+		Params:  []interface{}{srcChainID, srcBlockNumber, txIndex, logIndices},
+	}
+	if c.DestChainID != 0 {
+		request.Params = append(request.Params, c.DestChainID)
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	// Send request
+	body, err := c.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse JSON-RPC response
+	var response struct {
+		ID     int           `json:"id"`
+		Result []interface{} `json:"result"`
+		Error  *JSONRPCError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := checkResponseID(request.ID, response.ID, response.Error); err != nil {
+		return nil, err
+	}
+
+	if response.Error != nil {
+		if isMethodNotFound(response.Error) {
+			return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, RequestProofMultiMethod)
+		}
+		return nil, newRPCError(body, response.Error)
+	}
+
+	if len(response.Result) != len(logIndices) {
+		return nil, fmt.Errorf("API returned %d job IDs for %d log indices", len(response.Result), len(logIndices))
+	}
+
+	jobIDs := make([]string, len(logIndices))
+	for i, result := range response.Result {
+		jobID, err := jobIDFromResult(result)
+		if err != nil {
+			return nil, fmt.Errorf("log %d: %w", logIndices[i], err)
+		}
+		jobIDs[i] = jobID
+	}
+
+	if c.OnProofRequested != nil {
+		for i, jobID := range jobIDs {
+			c.OnProofRequested(jobID, srcChainID, srcBlockNumber, uint(txIndex), logIndices[i])
+		}
+	}
+
+	return jobIDs, nil
+}
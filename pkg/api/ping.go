@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Ping checks that the API is reachable and accepts the API key by sending
+// one cheap JSON-RPC call. It returns nil if the server answers without error,
+// and ErrInvalidAPIKey if it rejects the key.
+func (c *Client) Ping() error {
+	return c.PingContext(context.Background())
+}
+
+// PingContext is like Ping but uses ctx for the HTTP request
+func (c *Client) PingContext(ctx context.Context) error {
+	// Listing the supported chains takes no parameters. Only the envelope is
+	// checked, and a backend that does not implement the call has still
+	// answered, which is all a ping needs.
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID(),
+		Method:  "log_supportedChains",
+		Params:  []interface{}{},
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	c.debugf("Sending request to %s\n", c.APIBaseURL)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	body, err := c.post(ctx, reqBody)
+	if err != nil {
+		return err
+	}
+
+	var response JSONRPCResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := checkResponseID(request.ID, response.ID, response.Error); err != nil {
+		return err
+	}
+	if response.Error != nil && !isMethodNotFound(response.Error) {
+		return newRPCError(body, response.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRetryAfter is the longest Retry-After delay waited out when
+// MaxRetryAfter is not set
+const DefaultMaxRetryAfter = time.Minute
+
+// parseRetryAfter returns the delay of a Retry-After header, given either as
+// a number of seconds or as an HTTP date relative to now. It returns zero for
+// a missing or malformed header and for a date in the past.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds <= 0 || seconds > int64(24*time.Hour/time.Second) {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// retryAfter reports whether err is a 429 response worth waiting out, and for
+// how long
+func (c *Client) retryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	limit := c.MaxRetryAfter
+	if limit == 0 {
+		limit = DefaultMaxRetryAfter
+	}
+
+	return apiErr.RetryAfter, apiErr.RetryAfter <= limit
+}
+
+// sleepContext waits for d, or returns the context error if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pause waits for d like sleepContext, or through the sleep hook when set
+func (c *Client) pause(ctx context.Context, d time.Duration) error {
+	if c.sleep != nil {
+		return c.sleep(ctx, d)
+	}
+
+	return sleepContext(ctx, d)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{" 30 ", 30 * time.Second},
+		{"0", 0},
+		{"-3", 0},
+		{"86400", 24 * time.Hour},
+		{"86401", 0},
+		{"soon", 0},
+		{"1.5", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{now.Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+// rateLimitServer answers the first limited requests with 429 and the
+// Retry-After header retryAfter returns, if any, and later ones with a job ID.
+// It counts the requests it sees.
+func rateLimitServer(t *testing.T, limited int32, retryAfter func() string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+
+		if requests.Add(1) <= limited {
+			if header := retryAfter(); header != "" {
+				w.Header().Set("Retry-After", header)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"error":"rate limit exceeded"}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": 123})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter func() string
+		// minSleep and maxSleep bound the single wait before the retry
+		minSleep, maxSleep time.Duration
+	}{
+		{
+			name:       "seconds",
+			retryAfter: func() string { return "2" },
+			minSleep:   2 * time.Second,
+			maxSleep:   2 * time.Second,
+		},
+		{
+			name:       "HTTP date",
+			retryAfter: func() string { return time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat) },
+			// The date has a resolution of one second
+			minSleep: 8 * time.Second,
+			maxSleep: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := rateLimitServer(t, 1, tt.retryAfter)
+			client := NewClient("key", server.URL, 5*time.Second, false)
+			client.RetryMax = 3
+			sleeps := recordSleeps(client)
+
+			jobID, err := client.RequestProof(11155420, 24639225, 4, 1)
+			if err != nil {
+				t.Fatalf("RequestProof() error: %v", err)
+			}
+			if jobID != "123" {
+				t.Errorf("job ID = %q, want 123", jobID)
+			}
+			if n := requests.Load(); n != 2 {
+				t.Errorf("server saw %d requests, want 2", n)
+			}
+			if len(*sleeps) != 1 || (*sleeps)[0] < tt.minSleep || (*sleeps)[0] > tt.maxSleep {
+				t.Errorf("sleeps = %v, want one between %s and %s", *sleeps, tt.minSleep, tt.maxSleep)
+			}
+		})
+	}
+}
+
+func TestRateLimitNoRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		limited       int32
+		retryAfter    string
+		maxRetryAfter time.Duration
+		requests      int32
+		sleeps        int
+		wantDelay     time.Duration
+	}{
+		{
+			name:       "absent",
+			limited:    1,
+			retryAfter: "",
+			requests:   1,
+		},
+		{
+			name:       "over the default MaxRetryAfter",
+			limited:    1,
+			retryAfter: "120",
+			requests:   1,
+			wantDelay:  120 * time.Second,
+		},
+		{
+			name:          "over MaxRetryAfter",
+			limited:       1,
+			retryAfter:    "10",
+			maxRetryAfter: 5 * time.Second,
+			requests:      1,
+			wantDelay:     10 * time.Second,
+		},
+		{
+			name:          "negative MaxRetryAfter",
+			limited:       1,
+			retryAfter:    "1",
+			maxRetryAfter: -1,
+			requests:      1,
+			wantDelay:     time.Second,
+		},
+		{
+			name:       "second 429",
+			limited:    2,
+			retryAfter: "1",
+			requests:   2,
+			sleeps:     1,
+			wantDelay:  time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := rateLimitServer(t, tt.limited, func() string { return tt.retryAfter })
+			client := NewClient("key", server.URL, 5*time.Second, false)
+			client.RetryMax = 3
+			client.MaxRetryAfter = tt.maxRetryAfter
+			sleeps := recordSleeps(client)
+
+			_, err := client.RequestProof(11155420, 24639225, 4, 1)
+			if !errors.Is(err, ErrRateLimited) {
+				t.Fatalf("RequestProof() error = %v, want it to match ErrRateLimited", err)
+			}
+			if errors.Is(err, ErrUnreachable) {
+				t.Errorf("RequestProof() error = %v, want a 429 not to count as unreachable", err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+				t.Fatalf("RequestProof() error = %#v, want an APIError with status 429", err)
+			}
+			if apiErr.RetryAfter != tt.wantDelay {
+				t.Errorf("RetryAfter = %s, want %s", apiErr.RetryAfter, tt.wantDelay)
+			}
+			if n := requests.Load(); n != tt.requests {
+				t.Errorf("server saw %d requests, want %d", n, tt.requests)
+			}
+			if len(*sleeps) != tt.sleeps {
+				t.Errorf("sleeps = %v, want %d", *sleeps, tt.sleeps)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/stevenlei/polymer-cli/pkg/redact"
+
+// RedactAPIKey masks all but the last 4 characters of key. Keys of 4
+// characters or fewer are masked entirely.
+func RedactAPIKey(key string) string {
+	return redact.Secret(key)
+}
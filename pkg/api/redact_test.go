@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactAPIKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"", ""},
+		{"k", "*"},
+		{"k9z", "***"},
+		{"k9zq", "****"},
+		{"k9zqx", "*9zqx"},
+		{"pk_live_0123456789abcdef", "********************cdef"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactAPIKey(tt.key); got != tt.want {
+			t.Errorf("RedactAPIKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDebugOutputRedactsAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"long key", "pk_live_0123456789abcdef"},
+		{"short key", "k9zq"},
+		{"tiny key", "k9z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := statusServer(t, ProofStatusResponse{Status: "complete", Proof: []byte(`"AAAA"`)})
+			var out bytes.Buffer
+			client := NewClient(tt.key, server.URL, 5*time.Second, true)
+			client.DebugOutput = &out
+			// A key passed with --header as well must not leak either
+			client.Headers = http.Header{"X-Api-Key": {tt.key}}
+
+			if _, err := client.GetProofStatus("42"); err != nil {
+				t.Fatalf("GetProofStatus() error: %v", err)
+			}
+
+			debug := out.String()
+			if !strings.Contains(debug, "Request headers:") {
+				t.Fatalf("debug output has no request headers:\n%s", debug)
+			}
+			if strings.Contains(debug, tt.key) {
+				t.Errorf("debug output contains the API key %q:\n%s", tt.key, debug)
+			}
+			if !strings.Contains(debug, "Bearer "+RedactAPIKey(tt.key)) {
+				t.Errorf("debug output does not show the masked Authorization header:\n%s", debug)
+			}
+		})
+	}
+}
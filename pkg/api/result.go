@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/proof"
+)
+
+// ProofResult is a completed proof with its encoding already unwrapped
+type ProofResult struct {
+	JobID string
+	// Proof is the binary proof, decoded from the base64 or hex text the API
+	// returns
+	Proof []byte
+	// ChainID is the source chain ID read from the proof header
+	ChainID uint64
+	// BlockNumber is the source block number read from the proof header
+	BlockNumber uint64
+	// CompletedAt is when polling first saw the job complete; the API does
+	// not report its own completion time
+	CompletedAt time.Time
+	// Raw is the proof exactly as the API returned it
+	Raw json.RawMessage
+}
+
+// NewProofResult decodes the proof of a completed job
+func NewProofResult(jobID string, status *ProofStatusResponse, completedAt time.Time) (*ProofResult, error) {
+	// The proof is normally a JSON string holding base64 or hex text
+	text := string(status.Proof)
+	var s string
+	if err := json.Unmarshal(status.Proof, &s); err == nil {
+		text = s
+	}
+
+	data, err := proof.DecodeText(text)
+	if err != nil {
+		return nil, fmt.Errorf("malformed proof encoding: %w", err)
+	}
+
+	p, err := proof.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed proof: %w", err)
+	}
+
+	return &ProofResult{
+		JobID:       jobID,
+		Proof:       data,
+		ChainID:     uint64(p.ChainID),
+		BlockNumber: p.BlockNumber,
+		CompletedAt: completedAt,
+		Raw:         status.Proof,
+	}, nil
+}
+
+// WaitForProofResult is like WaitForProofContext but returns the decoded proof
+func (c *Client) WaitForProofResult(ctx context.Context, jobID string, maxAttempts int, interval time.Duration) (*ProofResult, error) {
+	status, err := c.WaitForProofContext(ctx, jobID, maxAttempts, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProofResult(jobID, status, time.Now())
+}
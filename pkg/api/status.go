@@ -0,0 +1,66 @@
+package api
+
+import "strings"
+
+// ProofStatus is the normalized state of a proof generation job
+type ProofStatus string
+
+// Canonical proof job states
+const (
+	ProofStatusPending    ProofStatus = "pending"
+	ProofStatusProcessing ProofStatus = "processing"
+	ProofStatusComplete   ProofStatus = "complete"
+	ProofStatusFailed     ProofStatus = "failed"
+	ProofStatusUnknown    ProofStatus = "unknown"
+)
+
+// proofStatusVariants maps the spellings used by different backend versions
+// onto the canonical states
+var proofStatusVariants = map[string]ProofStatus{
+	"pending":     ProofStatusPending,
+	"queued":      ProofStatusPending,
+	"submitted":   ProofStatusPending,
+	"created":     ProofStatusPending,
+	"processing":  ProofStatusProcessing,
+	"in_progress": ProofStatusProcessing,
+	"in-progress": ProofStatusProcessing,
+	"running":     ProofStatusProcessing,
+	"generating":  ProofStatusProcessing,
+	"complete":    ProofStatusComplete,
+	"completed":   ProofStatusComplete,
+	"success":     ProofStatusComplete,
+	"succeeded":   ProofStatusComplete,
+	"done":        ProofStatusComplete,
+	"ready":       ProofStatusComplete,
+	"failed":      ProofStatusFailed,
+	"failure":     ProofStatusFailed,
+	"error":       ProofStatusFailed,
+	"errored":     ProofStatusFailed,
+}
+
+// ParseProofStatus normalizes a status string returned by the API. Strings
+// it does not recognize map to ProofStatusUnknown rather than an error, so a
+// new backend status does not break existing clients.
+func ParseProofStatus(status string) ProofStatus {
+	if s, ok := proofStatusVariants[strings.ToLower(strings.TrimSpace(status))]; ok {
+		return s
+	}
+
+	return ProofStatusUnknown
+}
+
+// IsTerminal reports whether the job has finished, successfully or not
+func (s ProofStatus) IsTerminal() bool {
+	return s == ProofStatusComplete || s == ProofStatusFailed
+}
+
+// State returns the normalized status of the job
+func (r *ProofStatusResponse) State() ProofStatus {
+	return ParseProofStatus(r.Status)
+}
+
+// Ready reports whether the job is complete and its proof is available, using
+// the same normalized status as WaitForProof
+func (r *ProofStatusResponse) Ready() bool {
+	return r.State() == ProofStatusComplete && len(r.Proof) > 0
+}
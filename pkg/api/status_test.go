@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseProofStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   ProofStatus
+	}{
+		{"pending", ProofStatusPending},
+		{"queued", ProofStatusPending},
+		{"submitted", ProofStatusPending},
+		{"created", ProofStatusPending},
+		{"processing", ProofStatusProcessing},
+		{"in_progress", ProofStatusProcessing},
+		{"in-progress", ProofStatusProcessing},
+		{"running", ProofStatusProcessing},
+		{"generating", ProofStatusProcessing},
+		{"complete", ProofStatusComplete},
+		{"completed", ProofStatusComplete},
+		{"success", ProofStatusComplete},
+		{"succeeded", ProofStatusComplete},
+		{"done", ProofStatusComplete},
+		{"ready", ProofStatusComplete},
+		{"failed", ProofStatusFailed},
+		{"failure", ProofStatusFailed},
+		{"error", ProofStatusFailed},
+		{"errored", ProofStatusFailed},
+		{"COMPLETE", ProofStatusComplete},
+		{"  In_Progress\n", ProofStatusProcessing},
+		{"", ProofStatusUnknown},
+		{"unknown", ProofStatusUnknown},
+		{"cancelled", ProofStatusUnknown},
+		{"in progress", ProofStatusUnknown},
+		{"completed!", ProofStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := ParseProofStatus(tt.status); got != tt.want {
+				t.Errorf("ParseProofStatus(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProofStatusVariantsAreCovered(t *testing.T) {
+	// Every variant must be lower case to be reachable through ParseProofStatus
+	for variant, want := range proofStatusVariants {
+		if got := ParseProofStatus(variant); got != want {
+			t.Errorf("ParseProofStatus(%q) = %q, want %q", variant, got, want)
+		}
+	}
+}
+
+func TestProofStatusIsTerminal(t *testing.T) {
+	tests := []struct {
+		status ProofStatus
+		want   bool
+	}{
+		{ProofStatusPending, false},
+		{ProofStatusProcessing, false},
+		{ProofStatusComplete, true},
+		{ProofStatusFailed, true},
+		{ProofStatusUnknown, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.IsTerminal(); got != tt.want {
+			t.Errorf("%q.IsTerminal() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestProofStatusResponseReady(t *testing.T) {
+	proof := json.RawMessage(`"AAAA"`)
+
+	for variant, state := range proofStatusVariants {
+		t.Run(variant, func(t *testing.T) {
+			want := state == ProofStatusComplete
+			if got := (&ProofStatusResponse{Status: variant, Proof: proof}).Ready(); got != want {
+				t.Errorf("Ready() for %q with a proof = %v, want %v", variant, got, want)
+			}
+			if (&ProofStatusResponse{Status: variant}).Ready() {
+				t.Errorf("Ready() for %q without a proof = true, want false", variant)
+			}
+		})
+	}
+
+	for _, status := range []string{"", "unknown", "cancelled"} {
+		if (&ProofStatusResponse{Status: status, Proof: proof}).Ready() {
+			t.Errorf("Ready() for unrecognized status %q = true, want false", status)
+		}
+	}
+}
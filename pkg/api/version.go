@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+)
+
+// APIVersionHeader carries the requested API version, and the served one in
+// responses from servers that report it
+const APIVersionHeader = "X-API-Version"
+
+// checkAPIVersion warns, once per client, when the response suggests the
+// server does not support the requested API version: either it reports a
+// different version, or it rejected the request with an error mentioning the
+// version.
+func (c *Client) checkAPIVersion(resp *http.Response, body []byte) {
+	if c.APIVersion == "" {
+		return
+	}
+
+	served := resp.Header.Get(APIVersionHeader)
+	switch {
+	case served != "" && served != c.APIVersion:
+		c.warnVersion(fmt.Sprintf("requested API version %s but the server responded with version %s", c.APIVersion, served))
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && strings.Contains(strings.ToLower(string(body)), "version"):
+		c.warnVersion(fmt.Sprintf("the server may not support API version %s (status %d)", c.APIVersion, resp.StatusCode))
+	}
+}
+
+// warnVersion prints an API version warning the first time it is called
+func (c *Client) warnVersion(msg string) {
+	c.versionWarning.Do(func() {
+		c.logf(logging.LevelWarn, "%s\n", msg)
+	})
+}
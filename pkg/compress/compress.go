@@ -0,0 +1,63 @@
+// Package compress decodes compressed HTTP response bodies for the API and
+// RPC clients.
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// AcceptEncoding is the Accept-Encoding header value the clients send. Setting
+// it explicitly turns off net/http's own gzip handling, so responses must be
+// passed through Body.
+const AcceptEncoding = "gzip, deflate"
+
+// Body returns a reader for the decoded body of resp according to its
+// Content-Encoding. A response without one, from a server that ignored
+// Accept-Encoding, is returned as is. Closing resp.Body remains the caller's
+// job.
+func Body(resp *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip response: %w", err)
+		}
+		return r, nil
+	case "deflate":
+		return deflateReader(resp.Body)
+	default:
+		return nil, fmt.Errorf("unsupported response Content-Encoding %q", encoding)
+	}
+}
+
+// deflateReader decodes a deflate body. HTTP defines it as zlib-wrapped, but
+// some servers send a raw deflate stream, so that is accepted too.
+func deflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decompress deflate response: %w", err)
+	}
+
+	// A zlib header is a CMF byte for deflate (low nibble 8) whose 16-bit
+	// value with the FLG byte is a multiple of 31
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		r, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress deflate response: %w", err)
+		}
+		return r, nil
+	}
+
+	return flate.NewReader(br), nil
+}
@@ -3,26 +3,117 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
 )
 
 // Config represents the application configuration
 type Config struct {
+	Profile     string `mapstructure:"profile"`
 	APIKey      string `mapstructure:"api-key"`
+	APIKeyFile  string `mapstructure:"api-key-file"`
 	APIURL      string `mapstructure:"api-url"`
 	Debug       bool   `mapstructure:"debug"`
 	MaxAttempts int    `mapstructure:"max-attempts"`
 	Interval    int    `mapstructure:"interval"`
+	Timeout     int    `mapstructure:"timeout"`
+	RetryMax    int    `mapstructure:"retry-max"`
+	RetryBaseMs int    `mapstructure:"retry-base-ms"`
+	// IntervalJitter is a percentage by which each polling interval is
+	// randomly shortened or lengthened
+	IntervalJitter int `mapstructure:"interval-jitter"`
+	// TimeoutWait is the maximum total time to wait for a proof in
+	// milliseconds; zero means only max-attempts applies
+	TimeoutWait int `mapstructure:"timeout-wait"`
+	// PollErrorTolerance is the number of consecutive polls allowed to fail
+	// with a network error while waiting for a proof
+	PollErrorTolerance int `mapstructure:"poll-error-tolerance"`
+	// PollBackoff doubles the polling interval after every poll, up to
+	// PollMaxInterval milliseconds
+	PollBackoff     bool `mapstructure:"poll-backoff"`
+	PollMaxInterval int  `mapstructure:"poll-max-interval"`
+	// LogLevel is the verbosity of diagnostic messages: error, warn, info,
+	// debug or trace. Debug is true at the debug and trace levels.
+	LogLevel string `mapstructure:"log-level"`
+	// CheckAuth pings the API to validate the API key before requests that
+	// first do RPC lookups or submit many proofs
+	CheckAuth bool `mapstructure:"check-auth"`
+	// Network selects the API URL from Networks when api-url is not set
+	Network string `mapstructure:"network"`
+	// UserAgent overrides the default polymer-cli/<version> User-Agent header
+	UserAgent string `mapstructure:"user-agent"`
+	// Headers are added to every API and RPC request
+	Headers map[string]string `mapstructure:"headers"`
+	// HeaderOverride lets Headers replace the Authorization and Content-Type
+	// headers the clients set themselves
+	HeaderOverride bool `mapstructure:"header-override"`
+	// RPCAuthHeader and RPCBasicAuth are credentials sent to RPC endpoints
+	// only; see RPCAuth
+	RPCAuthHeader string `mapstructure:"rpc-auth-header"`
+	RPCBasicAuth  string `mapstructure:"rpc-basic-auth"`
+	// Proxy is an http(s) or socks5 proxy URL for all requests. When empty
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	Proxy string `mapstructure:"proxy"`
+	// CACert is a PEM bundle trusted in addition to the system roots
+	CACert string `mapstructure:"ca-cert"`
+	// InsecureSkipVerify disables TLS certificate verification
+	InsecureSkipVerify bool `mapstructure:"insecure-skip-verify"`
+	// APIVersion pins the Polymer API version sent with every request
+	APIVersion string `mapstructure:"api-version"`
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open
+	// to each API or RPC host for reuse
+	MaxIdleConnsPerHost int `mapstructure:"max-idle-conns-per-host"`
+	// MaxResponseBytes caps the size of an API or RPC response body, so a
+	// misbehaving endpoint cannot exhaust memory
+	MaxResponseBytes int `mapstructure:"max-response-bytes"`
+	// MethodRequest and MethodQuery are the JSON-RPC methods used to request
+	// a proof and query its status
+	MethodRequest string `mapstructure:"method-request"`
+	MethodQuery   string `mapstructure:"method-query"`
 }
 
+// ErrInvalidConfig matches, via errors.Is, every error returned by LoadConfig,
+// Validate, CheckFile, ApplyProfile, LogLevel, NetworkAPIURL and RPCAuth
+var ErrInvalidConfig = errors.New("invalid config")
+
+// configError marks err as a configuration problem without changing its message
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+
+func (e *configError) Unwrap() error { return e.err }
+
+func (e *configError) Is(target error) bool { return target == ErrInvalidConfig }
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() Config {
 	return Config{
-		APIURL:      "https://proof.testnet.polymer.zone",
-		Debug:       false,
-		MaxAttempts: 20,
-		Interval:    3000, // in milliseconds
+		APIURL:         "https://proof.testnet.polymer.zone",
+		Debug:          false,
+		MaxAttempts:    20,
+		Interval:       3000,  // in milliseconds
+		Timeout:        60000, // in milliseconds
+		RetryMax:       3,
+		RetryBaseMs:    500, // in milliseconds
+		IntervalJitter: 0,   // in percent
+		TimeoutWait:    0,   // in milliseconds
+
+		PollErrorTolerance:  3,
+		PollBackoff:         false,
+		PollMaxInterval:     60000, // in milliseconds
+		MaxIdleConnsPerHost: 16,
+		MaxResponseBytes:    64 << 20, // 64 MiB
+		MethodRequest:       api.DefaultRequestMethod,
+		MethodQuery:         api.DefaultQueryMethod,
+		LogLevel:            "info",
 	}
 }
 
@@ -30,7 +121,25 @@ func DefaultConfig() Config {
 func LoadConfig() (Config, error) {
 	defaultConfig := DefaultConfig()
 
+	if err := CheckFile(); err != nil {
+		return Config{}, err
+	}
+
+	if err := ApplyProfile(); err != nil {
+		return Config{}, err
+	}
+
 	// Set defaults if not explicitly provided
+	if network := viper.GetString("network"); network != "" {
+		networkURL, err := NetworkAPIURL(network)
+		if err != nil {
+			return Config{}, err
+		}
+		// An explicit api-url wins over the network preset
+		if !viper.IsSet("api-url") {
+			viper.Set("api-url", networkURL)
+		}
+	}
 	if !viper.IsSet("api-url") {
 		viper.Set("api-url", defaultConfig.APIURL)
 	}
@@ -43,28 +152,248 @@ func LoadConfig() (Config, error) {
 	if !viper.IsSet("interval") {
 		viper.Set("interval", defaultConfig.Interval)
 	}
+	if !viper.IsSet("timeout") {
+		viper.Set("timeout", defaultConfig.Timeout)
+	}
+	if !viper.IsSet("retry-max") {
+		viper.Set("retry-max", defaultConfig.RetryMax)
+	}
+	if !viper.IsSet("retry-base-ms") {
+		viper.Set("retry-base-ms", defaultConfig.RetryBaseMs)
+	}
+	if !viper.IsSet("interval-jitter") {
+		viper.Set("interval-jitter", defaultConfig.IntervalJitter)
+	}
+	if !viper.IsSet("timeout-wait") {
+		viper.Set("timeout-wait", defaultConfig.TimeoutWait)
+	}
+	if !viper.IsSet("poll-error-tolerance") {
+		viper.Set("poll-error-tolerance", defaultConfig.PollErrorTolerance)
+	}
+	if !viper.IsSet("poll-backoff") {
+		viper.Set("poll-backoff", defaultConfig.PollBackoff)
+	}
+	if !viper.IsSet("poll-max-interval") {
+		viper.Set("poll-max-interval", defaultConfig.PollMaxInterval)
+	}
+	if !viper.IsSet("max-idle-conns-per-host") {
+		viper.Set("max-idle-conns-per-host", defaultConfig.MaxIdleConnsPerHost)
+	}
+	if !viper.IsSet("max-response-bytes") {
+		viper.Set("max-response-bytes", defaultConfig.MaxResponseBytes)
+	}
+	if !viper.IsSet("method-request") {
+		viper.Set("method-request", defaultConfig.MethodRequest)
+	}
+	if !viper.IsSet("method-query") {
+		viper.Set("method-query", defaultConfig.MethodQuery)
+	}
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
-		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
+		return Config{}, &configError{fmt.Errorf("failed to unmarshal config: %w", err)}
+	}
+
+	level, err := LogLevel()
+	if err != nil {
+		return Config{}, err
+	}
+	config.LogLevel = level.String()
+	config.Debug = level >= logging.LevelDebug
+
+	// Commands that only talk to RPC endpoints never call Validate
+	if _, _, err := config.RPCAuth(); err != nil {
+		return Config{}, err
 	}
 
 	return config, nil
 }
 
+// ApplyProfile applies the profile selected with the profile key on top of
+// the top-level config file keys. LoadConfig calls it; call it directly only
+// to read profile settings before the config is loaded.
+func ApplyProfile() error {
+	profile := viper.GetString("profile")
+	if profile == "" {
+		return nil
+	}
+
+	if err := applyProfile(profile); err != nil {
+		return &configError{err}
+	}
+
+	return nil
+}
+
+// LogLevel returns the configured log level: log-level when it is set,
+// otherwise debug when debug is set and info by default
+func LogLevel() (logging.Level, error) {
+	if viper.IsSet("log-level") {
+		level, err := logging.ParseLevel(viper.GetString("log-level"))
+		if err != nil {
+			return logging.LevelInfo, &configError{err}
+		}
+		return level, nil
+	}
+	if viper.GetBool("debug") {
+		return logging.LevelDebug, nil
+	}
+
+	return logging.LevelInfo, nil
+}
+
+// applyProfile merges the named entry of the "profiles" map into the config
+// file layer, so flags and environment variables still take precedence
+func applyProfile(name string) error {
+	profiles := viper.GetStringMap("profiles")
+
+	// viper lowercases all keys, so match profile names case-insensitively
+	settings, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config file", name)
+	}
+
+	values, ok := settings.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("profile %q must be a map of config keys", name)
+	}
+
+	if err := viper.MergeConfigMap(values); err != nil {
+		return fmt.Errorf("failed to apply profile %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// apiKeyFilePrefix marks an api-key value that names a file holding the key
+const apiKeyFilePrefix = "file:"
+
+// ResolveAPIKey replaces a "file:" api-key reference, or an empty api-key when
+// api-key-file is set, with the key read from that file
+func (c *Config) ResolveAPIKey() error {
+	path := ""
+	switch {
+	case strings.HasPrefix(c.APIKey, apiKeyFilePrefix):
+		path = strings.TrimPrefix(c.APIKey, apiKeyFilePrefix)
+	case c.APIKey == "" && c.APIKeyFile != "":
+		path = c.APIKeyFile
+	default:
+		return nil
+	}
+
+	key, err := readAPIKeyFile(path)
+	if err != nil {
+		return err
+	}
+
+	c.APIKey = key
+	return nil
+}
+
+// readAPIKeyFile reads an API key from path, expanding a leading "~/" and
+// trimming surrounding whitespace. The key itself never appears in errors.
+func readAPIKeyFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand API key file path: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", path)
+	}
+
+	return key, nil
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
+	if err := c.validate(); err != nil {
+		return &configError{err}
+	}
+
+	return nil
+}
+
+func (c *Config) validate() error {
+	if err := c.ResolveAPIKey(); err != nil {
+		return err
+	}
+
 	if c.APIKey == "" {
-		return errors.New("API key is required. Set it using --api-key flag, POLYMER_API_KEY environment variable, or in the config file")
+		return errors.New("API key is required. Set it using --api-key or --api-key-file flag, POLYMER_API_KEY environment variable, or in the config file")
+	}
+
+	if !IsHTTPURL(c.APIURL) {
+		return fmt.Errorf("api-url must be a valid http(s) URL, got %q", c.APIURL)
+	}
+
+	for _, r := range intRanges {
+		value, err := c.Get(r.key)
+		if err != nil {
+			return err
+		}
+		if problem := r.problem(value.(int)); problem != "" {
+			return fmt.Errorf("%s %s", r.key, problem)
+		}
+	}
+
+	if strings.TrimSpace(c.MethodRequest) == "" {
+		return errors.New("method-request must not be empty")
+	}
+
+	if strings.TrimSpace(c.MethodQuery) == "" {
+		return errors.New("method-query must not be empty")
 	}
 
-	if c.MaxAttempts <= 0 {
-		return errors.New("max-attempts must be greater than 0")
+	if c.Proxy != "" && !IsProxyURL(c.Proxy) {
+		return fmt.Errorf("proxy must be an http, https, socks5 or socks5h URL, got %q", c.Proxy)
 	}
 
-	if c.Interval <= 0 {
-		return errors.New("interval must be greater than 0")
+	if c.CACert != "" {
+		if _, err := LoadCertPool(c.CACert); err != nil {
+			return err
+		}
+	}
+
+	for name := range c.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name %q in headers", name)
+		}
 	}
 
 	return nil
 }
+
+// IsHTTPURL reports whether raw is an absolute http or https URL with a host
+func IsHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// IsProxyURL reports whether raw is a proxy URL supported by net/http
+func IsProxyURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u.Host != ""
+	default:
+		return false
+	}
+}
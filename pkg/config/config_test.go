@@ -0,0 +1,312 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadFile writes content to a file called name in a temporary directory,
+// reads it the way initConfig does and returns the result of LoadConfig
+func loadFile(t *testing.T, name, content string) (Config, error) {
+	t.Helper()
+
+	readFile(t, name, content)
+	return LoadConfig()
+}
+
+// readFile writes content to a file called name in a temporary directory
+// and reads it into viper. viper is reset before and after, since it holds
+// global state.
+func readFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("ReadInConfig(%s) error: %v", name, err)
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "config.yaml",
+			content: `api-key: file-key
+api-url: https://proof.example.com
+max-attempts: 7
+poll-backoff: true
+headers:
+  X-Team: proofs
+`,
+		},
+		{
+			name: "config.yml",
+			content: `api-key: file-key
+api-url: https://proof.example.com
+max-attempts: 7
+poll-backoff: true
+headers:
+  X-Team: proofs
+`,
+		},
+		{
+			name: "config.toml",
+			content: `api-key = "file-key"
+api-url = "https://proof.example.com"
+max-attempts = 7
+poll-backoff = true
+
+[headers]
+X-Team = "proofs"
+`,
+		},
+		{
+			name: "config.json",
+			content: `{
+  "api-key": "file-key",
+  "api-url": "https://proof.example.com",
+  "max-attempts": 7,
+  "poll-backoff": true,
+  "headers": {"X-Team": "proofs"}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadFile(t, tt.name, tt.content)
+			if err != nil {
+				t.Fatalf("LoadConfig() error: %v", err)
+			}
+
+			if cfg.APIKey != "file-key" || cfg.APIURL != "https://proof.example.com" || cfg.MaxAttempts != 7 || !cfg.PollBackoff {
+				t.Errorf("LoadConfig() = %+v, want the values from the file", cfg)
+			}
+			// viper lower-cases map keys, whatever the format
+			if want := map[string]string{"x-team": "proofs"}; !reflect.DeepEqual(cfg.Headers, want) {
+				t.Errorf("Headers = %v, want %v", cfg.Headers, want)
+			}
+			// Keys the file leaves out keep their defaults
+			if def := DefaultConfig(); cfg.Interval != def.Interval || cfg.Timeout != def.Timeout {
+				t.Errorf("Interval, Timeout = %d, %d, want the defaults %d, %d", cfg.Interval, cfg.Timeout, def.Interval, def.Timeout)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	content := "api-url: https://file.example.com\nnetwork: mainnet\nmax-attempts: 7\ninterval: 1000\n"
+
+	t.Run("file over defaults and network", func(t *testing.T) {
+		cfg, err := loadFile(t, "config.yaml", content)
+		if err != nil {
+			t.Fatalf("LoadConfig() error: %v", err)
+		}
+		if cfg.APIURL != "https://file.example.com" || cfg.MaxAttempts != 7 || cfg.Interval != 1000 {
+			t.Errorf("LoadConfig() = %+v, want the values from the file", cfg)
+		}
+	})
+
+	t.Run("flags over file", func(t *testing.T) {
+		// Flags reach viper through BindPFlag; an explicit Set ranks the same
+		readFile(t, "config.yaml", content)
+		viper.Set("max-attempts", 2)
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig() error: %v", err)
+		}
+		if cfg.MaxAttempts != 2 || cfg.Interval != 1000 {
+			t.Errorf("MaxAttempts, Interval = %d, %d, want 2 from the flag and 1000 from the file", cfg.MaxAttempts, cfg.Interval)
+		}
+	})
+
+	t.Run("network without api-url", func(t *testing.T) {
+		cfg, err := loadFile(t, "config.yaml", "network: mainnet\n")
+		if err != nil {
+			t.Fatalf("LoadConfig() error: %v", err)
+		}
+		if want := Networks["mainnet"]; cfg.APIURL != want {
+			t.Errorf("APIURL = %q, want the mainnet preset %q", cfg.APIURL, want)
+		}
+	})
+}
+
+func TestIsHTTPURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://proof.testnet.polymer.zone", true},
+		{"http://localhost:8545", true},
+		{"https://proof.example.com/v1?x=1", true},
+		{"HTTPS://proof.example.com", true},
+		{"proof.testnet.polymer.zone", false},
+		{"//proof.testnet.polymer.zone", false},
+		{"ftp://proof.example.com", false},
+		{"ws://localhost:8546", false},
+		{"wss://rpc.example.com", false},
+		{"https://", false},
+		{"https:///path", false},
+		{"http:localhost", false},
+		{"", false},
+		{"https://exa mple.com", false},
+		{"://missing-scheme", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsHTTPURL(tt.url); got != tt.want {
+			t.Errorf("IsHTTPURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAPIURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://proof.testnet.polymer.zone", false},
+		{"http://127.0.0.1:8080/rpc", false},
+		{"proof.testnet.polymer.zone", true},
+		{"mailto:proofs@example.com", true},
+		{"wss://proof.example.com", true},
+		{"https://", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.APIKey = "key"
+			cfg.APIURL = tt.url
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() with api-url %q error = %v, want error: %v", tt.url, err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), "api-url must be a valid http(s) URL") {
+				t.Errorf("Validate() error = %q, want it to name api-url", err)
+			}
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Validate() error = %v, want it to match ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestIsProxyURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://proxy:3128", true},
+		{"https://proxy.example.com", true},
+		{"socks5://127.0.0.1:1080", true},
+		{"socks5h://127.0.0.1:1080", true},
+		{"socks4://127.0.0.1:1080", false},
+		{"proxy:3128", false},
+		{"http://", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsProxyURL(tt.url); got != tt.want {
+			t.Errorf("IsProxyURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	readFile(t, "config.yaml", "api-url: https://file.example.com\nmax-attempts: 7\ninterval: 1000\npoll-backoff: false\nmethod-query: file_query\n")
+	t.Setenv("POLYMER_API_URL", "https://env.example.com")
+	t.Setenv("POLYMER_MAX_ATTEMPTS", "9")
+	t.Setenv("POLYMER_POLL_BACKOFF", "true")
+	t.Setenv("POLYMER_METHOD_QUERY", "env_query")
+	t.Setenv("POLYMER_TIMEOUT_WAIT", "300000")
+	if err := BindEnv(); err != nil {
+		t.Fatalf("BindEnv() error: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	if cfg.APIURL != "https://env.example.com" || cfg.MaxAttempts != 9 || !cfg.PollBackoff || cfg.MethodQuery != "env_query" {
+		t.Errorf("LoadConfig() = %+v, want the environment to override the file", cfg)
+	}
+	if cfg.TimeoutWait != 300000 {
+		t.Errorf("TimeoutWait = %d, want 300000 from a key only set in the environment", cfg.TimeoutWait)
+	}
+	if cfg.Interval != 1000 {
+		t.Errorf("Interval = %d, want 1000 from the file", cfg.Interval)
+	}
+
+	// Flags, like an explicit Set, still win over the environment
+	viper.Set("max-attempts", 2)
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if cfg.MaxAttempts != 2 {
+		t.Errorf("MaxAttempts = %d, want 2 from the flag", cfg.MaxAttempts)
+	}
+}
+
+func TestBindEnvEveryKey(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	var keys []string
+	for _, field := range (Config{}).Fields() {
+		if field.Key == "headers" {
+			continue
+		}
+		keys = append(keys, field.Key)
+		t.Setenv(EnvVar(field.Key), "from-env-"+field.Key)
+	}
+	if len(keys) == 0 {
+		t.Fatal("Config has no fields")
+	}
+	if err := BindEnv(); err != nil {
+		t.Fatalf("BindEnv() error: %v", err)
+	}
+
+	for _, key := range keys {
+		if got, want := viper.GetString(key), "from-env-"+key; got != want {
+			t.Errorf("%s from %s = %q, want %q", key, EnvVar(key), got, want)
+		}
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	tests := map[string]string{
+		"api-key":              "POLYMER_API_KEY",
+		"max-attempts":         "POLYMER_MAX_ATTEMPTS",
+		"poll-error-tolerance": "POLYMER_POLL_ERROR_TOLERANCE",
+		"debug":                "POLYMER_DEBUG",
+	}
+	for key, want := range tests {
+		if got := EnvVar(key); got != want {
+			t.Errorf("EnvVar(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
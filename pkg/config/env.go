@@ -0,0 +1,37 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is prepended to the environment variable of every config key
+const EnvPrefix = "POLYMER"
+
+// EnvVar returns the environment variable for a config key, e.g.
+// POLYMER_MAX_ATTEMPTS for max-attempts
+func EnvVar(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// BindEnv binds every scalar config key to its environment variable. Keys
+// only known from the environment are otherwise missing when the config is
+// unmarshaled, and dashed keys would never match a variable name.
+func BindEnv() error {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || field.Type.Kind() == reflect.Map {
+			continue
+		}
+
+		if err := viper.BindEnv(key, EnvVar(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseHeader splits a "Key: Value" header argument
+func ParseHeader(s string) (string, string, error) {
+	key, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid header %q, expected \"Key: Value\"", s)
+	}
+
+	key = strings.TrimSpace(key)
+	if !validHeaderName(key) {
+		return "", "", fmt.Errorf("invalid header name %q", key)
+	}
+
+	return key, strings.TrimSpace(value), nil
+}
+
+// validHeaderName reports whether name is a non-empty HTTP header field name
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RPCAuth returns the header that carries the RPC credentials, or empty
+// strings when none are configured. rpc-auth-header is either a "Name: value"
+// header or the value of the Authorization header, e.g. "Bearer <token>";
+// rpc-basic-auth is "user:password".
+func (c Config) RPCAuth() (string, string, error) {
+	switch {
+	case c.RPCAuthHeader != "" && c.RPCBasicAuth != "":
+		return "", "", &configError{errors.New("rpc-auth-header and rpc-basic-auth cannot be used together")}
+	case c.RPCBasicAuth != "":
+		if !strings.Contains(c.RPCBasicAuth, ":") {
+			return "", "", &configError{errors.New("rpc-basic-auth must be \"user:password\"")}
+		}
+		return "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(c.RPCBasicAuth)), nil
+	case c.RPCAuthHeader != "":
+		// "Bearer a:b" has no valid header name before the colon, so it is
+		// taken as an Authorization value too
+		if name, value, ok := strings.Cut(c.RPCAuthHeader, ":"); ok && validHeaderName(strings.TrimSpace(name)) {
+			return strings.TrimSpace(name), strings.TrimSpace(value), nil
+		}
+		return "Authorization", strings.TrimSpace(c.RPCAuthHeader), nil
+	default:
+		return "", "", nil
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+)
+
+// Field is one config key and its value
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields returns the keys and values of c, in the order of the Config fields
+func (c Config) Fields() []Field {
+	v := reflect.ValueOf(c)
+	t := v.Type()
+	fields := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			fields = append(fields, Field{Key: key, Value: v.Field(i).Interface()})
+		}
+	}
+
+	return fields
+}
+
+// Get returns the value of key in c
+func (c Config) Get(key string) (interface{}, error) {
+	for _, field := range c.Fields() {
+		if field.Key == key {
+			return field.Value, nil
+		}
+	}
+
+	return nil, &configError{fmt.Errorf("unknown config key %q", key)}
+}
+
+// ParseValue converts a command-line value to the type of key, checking it
+// the way LoadConfig would. Map keys such as headers cannot be set this way.
+func ParseValue(key, value string) (interface{}, error) {
+	field, ok := fieldByKey(key)
+	if !ok {
+		return nil, &configError{fmt.Errorf("unknown config key %q", key)}
+	}
+
+	var parsed interface{}
+	switch field.Type.Kind() {
+	case reflect.String:
+		parsed = value
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, &configError{fmt.Errorf("invalid value %q for %s: expected an integer", value, key)}
+		}
+		parsed = n
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, &configError{fmt.Errorf("invalid value %q for %s: expected true or false", value, key)}
+		}
+		parsed = b
+	default:
+		return nil, &configError{fmt.Errorf("%s cannot be set from the command line, edit the config file instead", key)}
+	}
+
+	switch key {
+	case "log-level":
+		if _, err := logging.ParseLevel(value); err != nil {
+			return nil, &configError{err}
+		}
+	case "network":
+		if _, err := NetworkAPIURL(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsed, nil
+}
+
+// fieldByKey returns the Config field tagged with key
+func fieldByKey(key string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == key {
+			return t.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Networks maps each network preset to its Polymer API URL
+var Networks = map[string]string{
+	"testnet": "https://proof.testnet.polymer.zone",
+	"mainnet": "https://proof.polymer.zone",
+}
+
+// NetworkAPIURL returns the API URL of the named network preset. The name is
+// matched case-insensitively.
+func NetworkAPIURL(network string) (string, error) {
+	url, ok := Networks[strings.ToLower(strings.TrimSpace(network))]
+	if !ok {
+		return "", &configError{fmt.Errorf("unknown network %q, expected one of %s", network, strings.Join(NetworkNames(), ", "))}
+	}
+
+	return url, nil
+}
+
+// NetworkNames returns the network preset names in sorted order
+func NetworkNames() []string {
+	names := make([]string, 0, len(Networks))
+	for name := range Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+)
+
+// intRange bounds an integer config key; max is ignored when zero
+type intRange struct {
+	key      string
+	min, max int
+}
+
+// intRanges are the allowed values of the integer config keys, checked in
+// this order by Validate and for the keys a config file sets by CheckFile
+var intRanges = []intRange{
+	{key: "max-attempts", min: 1},
+	{key: "interval", min: 1},
+	{key: "timeout", min: 1},
+	{key: "retry-max", min: 0},
+	{key: "retry-base-ms", min: 1},
+	{key: "interval-jitter", min: 0, max: 100},
+	{key: "timeout-wait", min: 0},
+	{key: "poll-error-tolerance", min: 0},
+	{key: "poll-max-interval", min: 0},
+	{key: "max-idle-conns-per-host", min: 1},
+	{key: "max-response-bytes", min: 1},
+}
+
+// problem returns why n is out of range, or "" when it is allowed
+func (r intRange) problem(n int) string {
+	switch {
+	case r.max != 0 && (n < r.min || n > r.max):
+		return fmt.Sprintf("must be between %d and %d", r.min, r.max)
+	case n >= r.min:
+		return ""
+	case r.min == 0:
+		return "must not be negative"
+	case r.min == 1:
+		return "must be greater than 0"
+	default:
+		return fmt.Sprintf("must be at least %d", r.min)
+	}
+}
+
+// CheckFile checks the config file in use, if any, for keys it does not know,
+// values of the wrong type and integers out of range, at the top level and in
+// every profile. Viper would otherwise coerce such values or fall back to the
+// defaults without a word. A config file that does not exist yet is fine.
+func CheckFile() error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	// The root command ignores read errors, so read the file again to see them
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return &configError{fmt.Errorf("failed to read config file %s: %w", path, err)}
+	}
+
+	problems := checkSettings(v.AllSettings(), "")
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &configError{fmt.Errorf("invalid config file %s:\n  %s", path, strings.Join(problems, "\n  "))}
+}
+
+// checkSettings returns one message per problem in settings, naming each key
+// with prefix, which is empty at the top level of the file and
+// "profiles.<name>." inside a profile
+func checkSettings(settings map[string]interface{}, prefix string) []string {
+	var problems []string
+	for _, key := range sortedSettingKeys(settings) {
+		name, value := prefix+key, settings[key]
+
+		// Profiles hold config keys themselves, but cannot select or define
+		// other profiles
+		if key == "profiles" && prefix == "" {
+			profiles, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: expected a map of profile names to config keys, got %s", name, describeValue(value)))
+				continue
+			}
+			for _, profile := range sortedSettingKeys(profiles) {
+				keys, ok := profiles[profile].(map[string]interface{})
+				if !ok {
+					problems = append(problems, fmt.Sprintf("%s.%s: expected a map of config keys, got %s", name, profile, describeValue(profiles[profile])))
+					continue
+				}
+				problems = append(problems, checkSettings(keys, name+"."+profile+".")...)
+			}
+			continue
+		}
+
+		if key == "profile" && prefix != "" {
+			problems = append(problems, name+": a profile cannot select another profile")
+			continue
+		}
+		field, ok := fieldByKey(key)
+		if !ok {
+			problems = append(problems, name+": "+unknownKeyProblem(key, prefix == ""))
+			continue
+		}
+		if problem := checkValue(field, key, value); problem != "" {
+			problems = append(problems, name+": "+problem)
+			continue
+		}
+
+		// Check each entry of a map key such as headers
+		if entries, ok := value.(map[string]interface{}); ok {
+			for _, entry := range sortedSettingKeys(entries) {
+				switch entries[entry].(type) {
+				case map[string]interface{}, []interface{}, nil:
+					problems = append(problems, fmt.Sprintf("%s.%s: expected a string, got %s", name, entry, describeValue(entries[entry])))
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkValue returns why value cannot be used for the config field tagged
+// key, or "" when it can
+func checkValue(field reflect.StructField, key string, value interface{}) string {
+	switch field.Type.Kind() {
+	case reflect.Int:
+		n, ok := intValue(value)
+		if !ok {
+			return "expected an integer, got " + describeValue(value)
+		}
+		for _, r := range intRanges {
+			if r.key == key {
+				if problem := r.problem(n); problem != "" {
+					return fmt.Sprintf("%s, got %d", problem, n)
+				}
+			}
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return "expected true or false, got " + describeValue(value)
+		}
+	case reflect.String:
+		// Other scalars are turned into strings, e.g. an unquoted number, but
+		// YAML dates are not
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}:
+			return "expected a string, got " + describeValue(value)
+		case time.Time:
+			return fmt.Sprintf("expected a string, got the date %s; quote it", v.Format(time.DateOnly))
+		}
+		s := fmt.Sprint(value)
+		switch {
+		case key == "log-level" && value != nil:
+			if _, err := logging.ParseLevel(s); err != nil {
+				return err.Error()
+			}
+		case key == "network" && value != nil && s != "":
+			if _, err := NetworkAPIURL(s); err != nil {
+				return err.Error()
+			}
+		}
+	case reflect.Map:
+		if _, ok := value.(map[string]interface{}); !ok && value != nil {
+			return "expected a map of names to values, got " + describeValue(value)
+		}
+	}
+
+	return ""
+}
+
+// intValue converts a decoded number to an int. JSON files decode every
+// number as a float, so integral floats are accepted too.
+func intValue(value interface{}) (int, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt {
+			return 0, false
+		}
+		return int(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f > math.MaxInt || f < math.MinInt {
+			return 0, false
+		}
+		return int(f), true
+	}
+
+	return 0, false
+}
+
+// describeValue names the type of a decoded config value for messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "an empty value"
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case bool:
+		return fmt.Sprintf("the boolean %t", v)
+	case map[string]interface{}:
+		return "a map"
+	case []interface{}:
+		return "a list"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("the number %v", value)
+	}
+
+	return fmt.Sprintf("a %T", value)
+}
+
+// unknownKeyProblem reports an unknown key, suggesting the closest known key
+// when it looks like a typo of one
+func unknownKeyProblem(key string, top bool) string {
+	var known []string
+	for i, t := 0, reflect.TypeOf(Config{}); i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("mapstructure"); name != "" && (top || name != "profile") {
+			known = append(known, name)
+		}
+	}
+	if top {
+		known = append(known, "profiles")
+	}
+
+	best, bestDistance := "", 3
+	for _, name := range known {
+		if d := editDistance(key, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("unknown key, did you mean %s?", best)
+	}
+
+	return "unknown key"
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+// sortedSettingKeys returns the keys of settings in order, so problems are
+// reported the same way every time
+func sortedSettingKeys(settings map[string]interface{}) []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
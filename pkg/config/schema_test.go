@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// wantProblems are the problems CheckFile reports for each of the fixtures
+// in TestCheckFile, in order
+var wantProblems = []string{
+	"colour: unknown key",
+	`debug: expected true or false, got the string "yes"`,
+	"interval-jitter: must be between 0 and 100, got 150",
+	`max-attempts: expected an integer, got the string "many"`,
+	"profiles.staging.api-kye: unknown key, did you mean api-key?",
+	"profiles.staging.interval: must be greater than 0, got 0",
+	"profiles.staging.profile: a profile cannot select another profile",
+	"retry-max: must not be negative, got -1",
+	"timout: unknown key, did you mean timeout?",
+}
+
+func TestCheckFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "config.yaml",
+			content: `api-key: key
+timout: 30
+colour: blue
+debug: "yes"
+max-attempts: many
+interval-jitter: 150
+retry-max: -1
+profile: staging
+profiles:
+  staging:
+    api-url: https://staging.example.com
+    api-kye: staging-key
+    interval: 0
+    profile: prod
+`,
+		},
+		{
+			name: "config.json",
+			content: `{
+  "api-key": "key",
+  "timout": 30,
+  "colour": "blue",
+  "debug": "yes",
+  "max-attempts": "many",
+  "interval-jitter": 150,
+  "retry-max": -1,
+  "profile": "staging",
+  "profiles": {
+    "staging": {
+      "api-url": "https://staging.example.com",
+      "api-kye": "staging-key",
+      "interval": 0,
+      "profile": "prod"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "config.toml",
+			content: `api-key = "key"
+timout = 30
+colour = "blue"
+debug = "yes"
+max-attempts = "many"
+interval-jitter = 150
+retry-max = -1
+profile = "staging"
+
+[profiles.staging]
+api-url = "https://staging.example.com"
+api-kye = "staging-key"
+interval = 0
+profile = "prod"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readFile(t, tt.name, tt.content)
+
+			err := CheckFile()
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("CheckFile() error = %v, want it to match ErrInvalidConfig", err)
+			}
+			want := "invalid config file " + viper.ConfigFileUsed() + ":\n  " + strings.Join(wantProblems, "\n  ")
+			if err.Error() != want {
+				t.Errorf("CheckFile() error =\n%s\nwant\n%s", err, want)
+			}
+
+			// LoadConfig checks the file before decoding it
+			if _, err := LoadConfig(); err == nil || err.Error() != want {
+				t.Errorf("LoadConfig() error = %v, want the CheckFile error", err)
+			}
+		})
+	}
+}
+
+func TestCheckFileWrongTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"yaml date", "config.yaml", "user-agent: 2024-01-02\n", "user-agent: expected a string, got the date 2024-01-02; quote it"},
+		{"yaml list", "config.yaml", "api-url:\n  - https://proof.example.com\n", "api-url: expected a string, got a list"},
+		{"json fraction", "config.json", `{"timeout": 1.5}`, "timeout: expected an integer, got the number 1.5"},
+		{"json header map", "config.json", `{"headers": {"X-Team": {"name": "proofs"}}}`, "headers.x-team: expected a string, got a map"},
+		{"toml headers", "config.toml", "headers = \"X-Team: proofs\"\n", `headers: expected a map of names to values, got the string "X-Team: proofs"`},
+		{"toml profiles", "config.toml", "profiles = 1\n", "profiles: expected a map of profile names to config keys, got the number 1"},
+		{"toml profile", "config.toml", "[profiles]\nstaging = true\n", "profiles.staging: expected a map of config keys, got the boolean true"},
+		{"log level", "config.yaml", "log-level: loud\n", "log-level: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readFile(t, tt.file, tt.content)
+
+			err := CheckFile()
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("CheckFile() error = %v, want it to match ErrInvalidConfig", err)
+			}
+			problems := strings.Split(err.Error(), "\n  ")[1:]
+			if len(problems) != 1 || !strings.HasPrefix(problems[0], tt.want) {
+				t.Errorf("CheckFile() problems = %q, want one starting with %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFileParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", "api-key: key\n  interval: [5\n"},
+		{"config.json", `{"api-key": "key",}`},
+		{"config.toml", "api-key = key\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			// readFile would fail on the read, so point viper at the file
+			// the way the root command does, ignoring the error
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			viper.SetConfigFile(path)
+			_ = viper.ReadInConfig()
+
+			err := CheckFile()
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("CheckFile() error = %v, want it to match ErrInvalidConfig", err)
+			}
+			if want := "failed to read config file " + path + ": "; !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("CheckFile() error = %v, want it to start with %q", err, want)
+			}
+		})
+	}
+}
+
+func TestCheckFileMissing(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	if err := CheckFile(); err != nil {
+		t.Errorf("CheckFile() with no config file error = %v, want nil", err)
+	}
+
+	viper.SetConfigFile(filepath.Join(t.TempDir(), "config.yaml"))
+	if err := CheckFile(); err != nil {
+		t.Errorf("CheckFile() with a config file that does not exist error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// LoadCertPool returns the system roots plus every certificate in the PEM
+// bundle at path
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,183 @@
+// Package jobs keeps a local history of requested proof jobs, so a job can be
+// looked up again later without copying its ID around.
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxEntries is the number of most recent jobs kept in the cache
+const MaxEntries = 100
+
+// ErrNoJobs is returned by Last when no job has been recorded yet
+var ErrNoJobs = errors.New("no jobs recorded yet")
+
+// ErrNotCached is returned by Find when the job is not in the cache
+var ErrNotCached = errors.New("job not found in the job cache")
+
+// Entry is one requested proof job
+type Entry struct {
+	JobID       string    `json:"jobID"`
+	ChainID     uint64    `json:"chainId"`
+	BlockNumber uint64    `json:"blockNumber"`
+	TxIndex     uint      `json:"txIndex"`
+	LogIndex    uint      `json:"logIndex"`
+	DestChainID uint64    `json:"destChainId,omitempty"`
+	APIURL      string    `json:"apiUrl,omitempty"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// Store is a JSON file of entries, oldest first. It is safe for concurrent
+// use within one process.
+type Store struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns ~/.polymer-cli/jobs.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".polymer-cli", "jobs.json"), nil
+}
+
+// NewStore creates a Store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// List returns the recorded entries, oldest first. A missing file is an
+// empty list.
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Last returns the most recently recorded entry
+func (s *Store) Last() (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(entries) == 0 {
+		return Entry{}, ErrNoJobs
+	}
+
+	return entries[len(entries)-1], nil
+}
+
+// Find returns the most recently recorded entry for jobID
+func (s *Store) Find(jobID string) (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].JobID == jobID {
+			return entries[i], nil
+		}
+	}
+
+	return Entry{}, fmt.Errorf("%w: %s", ErrNotCached, jobID)
+}
+
+// Recent returns up to limit entries, most recent first, after skipping the
+// offset most recent ones. A limit of zero returns all remaining entries.
+// more reports whether older entries remain beyond the returned ones.
+func (s *Store) Recent(offset, limit int) (entries []Entry, more bool, err error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Walk from the newest entry backwards
+	for i := len(all) - 1 - offset; i >= 0; i-- {
+		if limit > 0 && len(entries) == limit {
+			return entries, true, nil
+		}
+		entries = append(entries, all[i])
+	}
+
+	return entries, false, nil
+}
+
+// Add appends an entry, dropping the oldest ones beyond MaxEntries
+func (s *Store) Add(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, entry)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	return s.write(entries)
+}
+
+func (s *Store) read() ([]Entry, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job cache: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse job cache %s: %w", s.Path, err)
+	}
+
+	return entries, nil
+}
+
+// write replaces the file atomically so a concurrent reader never sees a
+// partial list
+func (s *Store) write(entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job cache: %w", err)
+	}
+
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create job cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(s.Path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write job cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write job cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to move job cache into place: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package logging writes leveled diagnostic messages, so the verbosity of
+// progress and debug output can be chosen with a single setting.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of a message. A Logger prints messages at its own
+// level and every more severe one.
+type Level int
+
+// Levels from the most to the least severe
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+	LevelTrace
+)
+
+// levelNames are the names accepted by ParseLevel, indexed by Level
+var levelNames = []string{"error", "warn", "info", "debug", "trace"}
+
+// levelPrefixes start every message of a level; info messages are printed
+// as they are
+var levelPrefixes = []string{"ERROR: ", "WARNING: ", "", "DEBUG: ", "TRACE: "}
+
+// ParseLevel parses a level name such as "debug", ignoring case
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return Level(i), nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("invalid log level %q, expected one of %s", name, strings.Join(levelNames, ", "))
+}
+
+// String returns the level name
+func (l Level) String() string {
+	if l < LevelError || l > LevelTrace {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// Logger writes messages of its level and more severe ones. A nil *Logger
+// discards everything, so callers can log unconditionally. It is safe for
+// concurrent use.
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+// New creates a Logger writing messages up to level to w
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{w: w, level: level}
+}
+
+// Enabled reports whether messages of level are printed
+func (l *Logger) Enabled(level Level) bool {
+	return l != nil && level <= l.level
+}
+
+// Logf prints a message at level, prefixed with the level for anything but
+// info. The format is used as is, so it should end with a newline.
+func (l *Logger) Logf(level Level, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprint(l.w, levelPrefixes[level]+fmt.Sprintf(format, args...))
+}
+
+// Errorf prints an error message
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Logf(LevelError, format, args...)
+}
+
+// Warnf prints a warning
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Logf(LevelWarn, format, args...)
+}
+
+// Infof prints a progress message
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Logf(LevelInfo, format, args...)
+}
+
+// Debugf prints a debug message
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Logf(LevelDebug, format, args...)
+}
+
+// Tracef prints a trace message, for details too noisy for debug
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.Logf(LevelTrace, format, args...)
+}
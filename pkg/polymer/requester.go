@@ -0,0 +1,290 @@
+// Package polymer requests Polymer log proofs for transactions, combining the
+// chain RPC lookups and log selection with the Polymer proof API. It is the
+// library behind the polymer-cli request command.
+package polymer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+// ErrChainIDUnknown is returned when the transaction has no chain ID, no
+// override was given and the RPC endpoint could not report one
+var ErrChainIDUnknown = errors.New("chain ID not found in transaction")
+
+// ChainIDSource records where a resolved chain ID came from
+type ChainIDSource string
+
+const (
+	// ChainIDFromOptions means the chain ID was given with TxHashOptions.ChainID
+	ChainIDFromOptions ChainIDSource = "options"
+	// ChainIDFromTransaction means the chain ID was read from the transaction's chainId field
+	ChainIDFromTransaction ChainIDSource = "transaction"
+	// ChainIDFromNode means the transaction had no chain ID, so eth_chainId was asked
+	ChainIDFromNode ChainIDSource = "eth_chainId"
+)
+
+// TxHashOptions controls how a transaction is turned into proof requests
+type TxHashOptions struct {
+	// ChainID overrides the chain ID derived from the transaction when non-zero
+	ChainID uint64
+	// LogIndex selects a log by its position in the receipt. When nil, the
+	// logs matching EventSignature and/or LogAddress are used, or the first
+	// log when neither is set.
+	LogIndex *uint
+	// EventSignature filters logs by event, e.g. "Transfer(address,address,uint256)"
+	EventSignature string
+	// EventSignatures are alternatives to EventSignature; a log emitting any
+	// of the given events matches
+	EventSignatures []string
+	// LogAddress filters logs by the contract that emitted them
+	LogAddress string
+	// AllMatches requests a proof for every matching log instead of the first
+	AllMatches bool
+	// Occurrence selects the Nth matching log (1-based) instead of the first
+	// when non-zero
+	Occurrence uint
+}
+
+// ResolvedTx holds the proof request parameters derived from a transaction
+type ResolvedTx struct {
+	Transaction   *rpc.Transaction
+	Receipt       *rpc.TransactionReceipt
+	ChainID       uint64
+	ChainIDSource ChainIDSource
+	BlockNumber   uint64
+	TxIndex       uint64
+	// LogIndices are the receipt positions of every selected log, in order
+	LogIndices []int
+	// MatchedSignatures holds the event signature each selected log matched,
+	// in the same order as LogIndices. Entries are empty when logs were not
+	// selected by signature.
+	MatchedSignatures []string
+}
+
+// ProofRequester requests proofs for transactions identified by hash
+type ProofRequester struct {
+	API *api.Client
+	RPC *rpc.RPCClient
+}
+
+// NewProofRequester creates a ProofRequester using apiClient for proof
+// requests and rpcClient for chain lookups
+func NewProofRequester(apiClient *api.Client, rpcClient *rpc.RPCClient) *ProofRequester {
+	return &ProofRequester{API: apiClient, RPC: rpcClient}
+}
+
+// RequestProofByTxHash resolves txHash and requests a proof for the selected
+// log, or for every matching log with opts.AllMatches. It returns one job ID
+// per requested log.
+func (r *ProofRequester) RequestProofByTxHash(ctx context.Context, txHash string, opts TxHashOptions) ([]string, error) {
+	resolved, err := r.ResolveTxHash(ctx, txHash, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.RequestProofs(ctx, resolved, opts.AllMatches)
+}
+
+// ResolveTxHash fetches the transaction and its receipt and works out the
+// chain ID, block number, transaction index and matching logs, without
+// requesting anything from the Polymer API
+func (r *ProofRequester) ResolveTxHash(ctx context.Context, txHash string, opts TxHashOptions) (*ResolvedTx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, receipt, err := r.RPC.GetTransactionAndReceipt(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedTx{Transaction: tx, Receipt: receipt}
+
+	resolved.BlockNumber, err = rpc.HexToUint64(receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number in receipt: %w", err)
+	}
+
+	resolved.TxIndex, err = rpc.HexToUint64(receipt.TransactionIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction index in receipt: %w", err)
+	}
+
+	// Prefer an explicit chain ID over the transaction's own
+	switch {
+	case opts.ChainID != 0:
+		resolved.ChainID = opts.ChainID
+		resolved.ChainIDSource = ChainIDFromOptions
+	case tx.ChainID != "":
+		resolved.ChainID, err = rpc.HexToUint64(tx.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain ID in transaction: %w", err)
+		}
+		resolved.ChainIDSource = ChainIDFromTransaction
+	default:
+		// Legacy (pre-EIP-155) transactions carry no chain ID, so ask the node instead
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resolved.ChainID, err = r.RPC.GetChainID()
+		if err != nil {
+			return nil, fmt.Errorf("%w and eth_chainId failed (%v)", ErrChainIDUnknown, err)
+		}
+		resolved.ChainIDSource = ChainIDFromNode
+	}
+
+	resolved.LogIndices, resolved.MatchedSignatures, err = SelectLogs(receipt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// RequestProofs requests a proof for the first selected log of resolved, or
+// for all of them when allMatches is set, and returns the job IDs in order
+func (r *ProofRequester) RequestProofs(ctx context.Context, resolved *ResolvedTx, allMatches bool) ([]string, error) {
+	indices := resolved.LogIndices
+	if !allMatches {
+		indices = indices[:1]
+	}
+
+	jobIDs := make([]string, 0, len(indices))
+	for _, logIdx := range indices {
+		jobID, err := r.API.RequestProofContext(ctx, resolved.ChainID, resolved.BlockNumber, uint(resolved.TxIndex), uint(logIdx))
+		if err != nil {
+			if allMatches {
+				return jobIDs, fmt.Errorf("failed to request proof for log %d: %w", logIdx, err)
+			}
+			return nil, fmt.Errorf("failed to request proof: %w", err)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, nil
+}
+
+// signatures returns EventSignature followed by EventSignatures, skipping
+// empty entries
+func (o TxHashOptions) signatures() []string {
+	var sigs []string
+	for _, sig := range append([]string{o.EventSignature}, o.EventSignatures...) {
+		if strings.TrimSpace(sig) != "" {
+			sigs = append(sigs, sig)
+		}
+	}
+
+	return sigs
+}
+
+// describeFilters names the log filters in use, e.g. for an error message
+func describeFilters(eventSigs []string, address string) string {
+	switch {
+	case len(eventSigs) > 0 && address != "":
+		return fmt.Sprintf("event signature %s from address %s", strings.Join(eventSigs, " or "), address)
+	case len(eventSigs) > 0:
+		return "event signature " + strings.Join(eventSigs, " or ")
+	default:
+		return "logs from address " + address
+	}
+}
+
+// SelectLogs returns the indices of the receipt logs to prove and the event
+// signature each one matched. An explicit log index wins; otherwise every log
+// matching any of the event signatures and/or the emitting address is
+// returned in order, or only the opts.Occurrence-th of them. With no filters
+// the first log is used.
+func SelectLogs(receipt *rpc.TransactionReceipt, opts TxHashOptions) ([]int, []string, error) {
+	if len(receipt.Logs) == 0 {
+		return nil, nil, fmt.Errorf("no logs found in transaction receipt")
+	}
+
+	eventSigs, address := opts.signatures(), opts.LogAddress
+
+	// Case 1: User specified log index
+	if opts.LogIndex != nil {
+		if int(*opts.LogIndex) >= len(receipt.Logs) {
+			return nil, nil, fmt.Errorf("log index %d is out of range, transaction has %d logs", *opts.LogIndex, len(receipt.Logs))
+		}
+
+		return []int{int(*opts.LogIndex)}, []string{""}, nil
+	}
+
+	// Case 2: No log index, event signature or address provided, use the first log
+	if len(eventSigs) == 0 && address == "" {
+		return []int{0}, []string{""}, nil
+	}
+
+	// Case 3: User specified event signatures and/or an emitting contract address
+	eventHashes := make([]string, len(eventSigs))
+	for i, eventSig := range eventSigs {
+		// Canonicalize the signature so spacing, parameter names and type
+		// aliases don't change the hash
+		normalizedSig, err := rpc.CanonicalEventSignature(eventSig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		eventHashes[i], err = rpc.EventSignatureHash(normalizedSig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get event signature hash: %w", err)
+		}
+	}
+
+	// Count how many logs each filter accepts so a failed search can say
+	// which one excluded the candidates
+	var matches []int
+	var matched []string
+	signatureMatches, addressMatches := 0, 0
+	for i, log := range receipt.Logs {
+		// The first topic is the event signature hash
+		sigOK, sig := len(eventHashes) == 0, ""
+		for j, eventHash := range eventHashes {
+			if len(log.Topics) > 0 && strings.EqualFold(log.Topics[0], eventHash) {
+				sigOK, sig = true, eventSigs[j]
+				break
+			}
+		}
+		addrOK := address == "" || strings.EqualFold(log.Address, address)
+		if sigOK {
+			signatureMatches++
+		}
+		if addrOK {
+			addressMatches++
+		}
+		if sigOK && addrOK {
+			matches = append(matches, i)
+			matched = append(matched, sig)
+		}
+	}
+
+	if len(matches) == 0 {
+		eventSig := strings.Join(eventSigs, " or ")
+		switch {
+		case len(eventSigs) > 0 && address != "" && signatureMatches > 0:
+			return nil, nil, fmt.Errorf("no log found with event signature %s from address %s: %d logs match the signature but none was emitted by that address", eventSig, address, signatureMatches)
+		case len(eventSigs) > 0 && address != "" && addressMatches > 0:
+			return nil, nil, fmt.Errorf("no log found with event signature %s from address %s: %d logs were emitted by that address but none matches the signature", eventSig, address, addressMatches)
+		case len(eventSigs) > 0:
+			return nil, nil, fmt.Errorf("no log found with event signature: %s", eventSig)
+		default:
+			return nil, nil, fmt.Errorf("no log found from address: %s", address)
+		}
+	}
+
+	if opts.Occurrence > 0 {
+		if int(opts.Occurrence) > len(matches) {
+			return nil, nil, fmt.Errorf("occurrence %d of %s requested but only %d logs match", opts.Occurrence, describeFilters(eventSigs, address), len(matches))
+		}
+		n := opts.Occurrence - 1
+		return matches[n : n+1], matched[n : n+1], nil
+	}
+
+	return matches, matched, nil
+}
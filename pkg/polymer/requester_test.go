@@ -0,0 +1,292 @@
+package polymer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/api"
+	"github.com/stevenlei/polymer-cli/pkg/rpc"
+)
+
+const (
+	transferSig   = "Transfer(address,address,uint256)"
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	approvalSig   = "Approval(address,address,uint256)"
+	approvalTopic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+
+	tokenA = "0x1111111111111111111111111111111111111111"
+	tokenB = "0x2222222222222222222222222222222222222222"
+)
+
+// fixtureReceipt has an Approval and three Transfers from two tokens, and a
+// log without topics
+func fixtureReceipt() *rpc.TransactionReceipt {
+	return &rpc.TransactionReceipt{
+		BlockNumber:      "0x177f6f9",
+		TransactionIndex: "0x4",
+		Logs: []rpc.Log{
+			{Address: tokenA, Topics: []string{approvalTopic}},
+			{Address: tokenA, Topics: []string{transferTopic}},
+			// Topics are compared case-insensitively
+			{Address: tokenB, Topics: []string{"0x" + strings.ToUpper(transferTopic[2:])}},
+			{Address: tokenA, Topics: []string{transferTopic}},
+			{Address: tokenA},
+		},
+	}
+}
+
+func uintPtr(n uint) *uint { return &n }
+
+func TestSelectLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TxHashOptions
+		indices []int
+		sigs    []string
+	}{
+		{
+			name:    "no filters uses the first log",
+			indices: []int{0},
+			sigs:    []string{""},
+		},
+		{
+			name:    "log index",
+			opts:    TxHashOptions{LogIndex: uintPtr(3), EventSignature: approvalSig},
+			indices: []int{3},
+			sigs:    []string{""},
+		},
+		{
+			name:    "event signature",
+			opts:    TxHashOptions{EventSignature: transferSig},
+			indices: []int{1, 2, 3},
+			sigs:    []string{transferSig, transferSig, transferSig},
+		},
+		{
+			name:    "non-canonical event signature",
+			opts:    TxHashOptions{EventSignature: "event Transfer(address indexed from, address indexed to, uint value)"},
+			indices: []int{1, 2, 3},
+			sigs: []string{
+				"event Transfer(address indexed from, address indexed to, uint value)",
+				"event Transfer(address indexed from, address indexed to, uint value)",
+				"event Transfer(address indexed from, address indexed to, uint value)",
+			},
+		},
+		{
+			name:    "any of several signatures",
+			opts:    TxHashOptions{EventSignature: transferSig, EventSignatures: []string{approvalSig}},
+			indices: []int{0, 1, 2, 3},
+			sigs:    []string{approvalSig, transferSig, transferSig, transferSig},
+		},
+		{
+			name:    "address",
+			opts:    TxHashOptions{LogAddress: tokenB},
+			indices: []int{2},
+			sigs:    []string{""},
+		},
+		{
+			name:    "address is case-insensitive",
+			opts:    TxHashOptions{LogAddress: "0x" + strings.ToUpper(tokenA[2:])},
+			indices: []int{0, 1, 3, 4},
+			sigs:    []string{"", "", "", ""},
+		},
+		{
+			name:    "signature and address",
+			opts:    TxHashOptions{EventSignature: transferSig, LogAddress: tokenA},
+			indices: []int{1, 3},
+			sigs:    []string{transferSig, transferSig},
+		},
+		{
+			name:    "first occurrence",
+			opts:    TxHashOptions{EventSignature: transferSig, Occurrence: 1},
+			indices: []int{1},
+			sigs:    []string{transferSig},
+		},
+		{
+			name:    "second occurrence",
+			opts:    TxHashOptions{EventSignature: transferSig, Occurrence: 2},
+			indices: []int{2},
+			sigs:    []string{transferSig},
+		},
+		{
+			name:    "last occurrence with address",
+			opts:    TxHashOptions{EventSignature: transferSig, LogAddress: tokenA, Occurrence: 2},
+			indices: []int{3},
+			sigs:    []string{transferSig},
+		},
+		{
+			name:    "occurrence of an address",
+			opts:    TxHashOptions{LogAddress: tokenA, Occurrence: 4},
+			indices: []int{4},
+			sigs:    []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indices, sigs, err := SelectLogs(fixtureReceipt(), tt.opts)
+			if err != nil {
+				t.Fatalf("SelectLogs() error: %v", err)
+			}
+			if !reflect.DeepEqual(indices, tt.indices) {
+				t.Errorf("indices = %v, want %v", indices, tt.indices)
+			}
+			if !reflect.DeepEqual(sigs, tt.sigs) {
+				t.Errorf("signatures = %q, want %q", sigs, tt.sigs)
+			}
+		})
+	}
+}
+
+func TestSelectLogsErrors(t *testing.T) {
+	const tokenC = "0x3333333333333333333333333333333333333333"
+
+	tests := []struct {
+		name    string
+		receipt *rpc.TransactionReceipt
+		opts    TxHashOptions
+		want    string
+	}{
+		{
+			name:    "no logs",
+			receipt: &rpc.TransactionReceipt{},
+			want:    "no logs found in transaction receipt",
+		},
+		{
+			name: "log index out of range",
+			opts: TxHashOptions{LogIndex: uintPtr(5)},
+			want: "log index 5 is out of range, transaction has 5 logs",
+		},
+		{
+			name: "no matching signature",
+			opts: TxHashOptions{EventSignature: "Paused()"},
+			want: "no log found with event signature: Paused()",
+		},
+		{
+			name: "no matching address",
+			opts: TxHashOptions{LogAddress: tokenC},
+			want: "no log found from address: " + tokenC,
+		},
+		{
+			name: "signature matches elsewhere",
+			opts: TxHashOptions{EventSignature: approvalSig, LogAddress: tokenB},
+			want: "1 logs match the signature but none was emitted by that address",
+		},
+		{
+			name: "address matches another event",
+			opts: TxHashOptions{EventSignature: "Paused()", LogAddress: tokenB},
+			want: "1 logs were emitted by that address but none matches the signature",
+		},
+		{
+			name: "invalid signature",
+			opts: TxHashOptions{EventSignature: "Transfer(address"},
+			want: "expected Name(type,...)",
+		},
+		{
+			name: "occurrence beyond the matches",
+			opts: TxHashOptions{EventSignature: transferSig, Occurrence: 4},
+			want: "occurrence 4 of event signature " + transferSig + " requested but only 3 logs match",
+		},
+		{
+			name: "occurrence beyond the matches with address",
+			opts: TxHashOptions{EventSignature: transferSig, LogAddress: tokenB, Occurrence: 2},
+			want: "occurrence 2 of event signature " + transferSig + " from address " + tokenB + " requested but only 1 logs match",
+		},
+		{
+			name: "occurrence without a match",
+			opts: TxHashOptions{EventSignature: "Paused()", Occurrence: 1},
+			want: "no log found with event signature: Paused()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := tt.receipt
+			if receipt == nil {
+				receipt = fixtureReceipt()
+			}
+			_, _, err := SelectLogs(receipt, tt.opts)
+			if err == nil {
+				t.Fatalf("SelectLogs() succeeded, want an error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SelectLogs() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// proofAPIServer answers every proof request with a job ID of 100 plus the
+// log index, and records the log indices requested
+func proofAPIServer(t *testing.T) (*httptest.Server, func() []int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var logIndices []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			ID     int   `json:"id"`
+			Params []int `json:"params"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || len(request.Params) != 4 {
+			t.Errorf("invalid request body %q: %v", body, err)
+			return
+		}
+
+		logIndex := request.Params[3]
+		mu.Lock()
+		logIndices = append(logIndices, logIndex)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": 100 + logIndex})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return logIndices
+	}
+}
+
+func TestRequestProofsAllMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		allMatches bool
+		jobIDs     []string
+	}{
+		{"first match", false, []string{"101"}},
+		{"all matches", true, []string{"101", "102", "103"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requested := proofAPIServer(t)
+			r := NewProofRequester(api.NewClient("key", server.URL, 5*time.Second, false), nil)
+
+			indices, sigs, err := SelectLogs(fixtureReceipt(), TxHashOptions{EventSignature: transferSig, AllMatches: tt.allMatches})
+			if err != nil {
+				t.Fatalf("SelectLogs() error: %v", err)
+			}
+			resolved := &ResolvedTx{ChainID: 11155420, BlockNumber: 24639225, TxIndex: 4, LogIndices: indices, MatchedSignatures: sigs}
+
+			jobIDs, err := r.RequestProofs(context.Background(), resolved, tt.allMatches)
+			if err != nil {
+				t.Fatalf("RequestProofs() error: %v", err)
+			}
+			if !reflect.DeepEqual(jobIDs, tt.jobIDs) {
+				t.Errorf("job IDs = %v, want %v", jobIDs, tt.jobIDs)
+			}
+			if got := len(requested()); got != len(tt.jobIDs) {
+				t.Errorf("API saw %d requests, want %d", got, len(tt.jobIDs))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package proof
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ArtifactVersion is the schema version written by NewArtifact. ReadArtifact
+// rejects artifacts of any other version.
+const ArtifactVersion = 1
+
+// Artifact is a completed proof job exported to a single JSON file, so it can
+// be carried to another machine and checked there without the API
+type Artifact struct {
+	Version    int       `json:"version"`
+	JobID      string    `json:"jobId"`
+	Status     string    `json:"status"`
+	APIURL     string    `json:"apiUrl,omitempty"`
+	CLIVersion string    `json:"cliVersion,omitempty"`
+	ExportedAt time.Time `json:"exportedAt"`
+
+	// Request holds the parameters the proof was requested with, when they
+	// are known
+	Request *ArtifactRequest `json:"request,omitempty"`
+
+	// Proof is the proof as returned by the API, and ProofSHA256 the hex
+	// SHA-256 of its decoded bytes
+	Proof       string `json:"proof"`
+	ProofSHA256 string `json:"proofSha256"`
+}
+
+// ArtifactRequest is the log a proof was requested for
+type ArtifactRequest struct {
+	ChainID     uint64 `json:"chainId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxIndex     uint   `json:"txIndex"`
+	LogIndex    uint   `json:"logIndex"`
+	DestChainID uint64 `json:"destChainId,omitempty"`
+}
+
+// NewArtifact creates an artifact for a proof in the textual form returned
+// by the API, recording the checksum of its decoded bytes
+func NewArtifact(jobID, status, proofText string) (*Artifact, error) {
+	data, err := DecodeText(proofText)
+	if err != nil {
+		return nil, fmt.Errorf("malformed proof encoding: %w", err)
+	}
+
+	return &Artifact{
+		Version:     ArtifactVersion,
+		JobID:       jobID,
+		Status:      status,
+		ExportedAt:  time.Now().UTC(),
+		Proof:       strings.TrimSpace(proofText),
+		ProofSHA256: checksum(data),
+	}, nil
+}
+
+// ReadArtifact decodes an artifact and checks its schema version. Use
+// Validate to check its contents.
+func ReadArtifact(r io.Reader) (*Artifact, error) {
+	var a Artifact
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("malformed proof artifact: %w", err)
+	}
+	if a.Version != ArtifactVersion {
+		return nil, fmt.Errorf("unsupported proof artifact version %d, expected %d", a.Version, ArtifactVersion)
+	}
+
+	return &a, nil
+}
+
+// Write encodes the artifact as indented JSON
+func (a *Artifact) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// Decode returns the decoded proof held by the artifact
+func (a *Artifact) Decode() (*Proof, error) {
+	return Parse([]byte(a.Proof))
+}
+
+// Validate checks that the proof matches its checksum, passes Proof.Validate
+// and, when the request is known, was generated for that log. All failures
+// are reported together.
+func (a *Artifact) Validate() error {
+	if a.JobID == "" {
+		return errors.New("artifact has no job ID")
+	}
+
+	data, err := DecodeText(a.Proof)
+	if err != nil {
+		return fmt.Errorf("malformed proof encoding: %w", err)
+	}
+	if sum := checksum(data); !strings.EqualFold(sum, a.ProofSHA256) {
+		return fmt.Errorf("proof checksum %s does not match %s recorded in the artifact", sum, a.ProofSHA256)
+	}
+
+	p, err := Decode(data)
+	if err != nil {
+		return fmt.Errorf("malformed proof: %w", err)
+	}
+
+	// Flatten the proof's own failures so each is reported on its own
+	var errs []error
+	if err := p.Validate(); err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			errs = append(errs, joined.Unwrap()...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if r := a.Request; r != nil {
+		if uint64(p.ChainID) != r.ChainID {
+			errs = append(errs, fmt.Errorf("proof is for chain %d, but chain %d was requested", p.ChainID, r.ChainID))
+		}
+		if p.BlockNumber != r.BlockNumber {
+			errs = append(errs, fmt.Errorf("proof is for block %d, but block %d was requested", p.BlockNumber, r.BlockNumber))
+		}
+		if uint(p.ReceiptIndex) != r.TxIndex {
+			errs = append(errs, fmt.Errorf("proof is for transaction index %d, but %d was requested", p.ReceiptIndex, r.TxIndex))
+		}
+		if uint(p.LogIndex) != r.LogIndex {
+			errs = append(errs, fmt.Errorf("proof is for log index %d, but %d was requested", p.LogIndex, r.LogIndex))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checksum returns the hex SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
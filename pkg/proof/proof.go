@@ -0,0 +1,164 @@
+package proof
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Proof layout, matching what the Polymer CrossL2ProverV2 contract reads:
+//
+//	[0:32]          Polymer state root (app hash)
+//	[32:97]         sequencer signature over the state root
+//	[97:105]        Polymer height
+//	[105:109]       source chain ID
+//	[109:117]       source block number
+//	[117:119]       receipt (transaction) index
+//	[119]           log index
+//	[120]           number of topics
+//	[121:123]       end offset of the event
+//	[123:eventEnd]  emitting contract (20 bytes), topics (32 bytes each), data
+//	[eventEnd:]     IAVL inclusion proof
+const (
+	signatureLength = 65
+	headerLength    = 123
+	addressLength   = 20
+	topicLength     = 32
+	maxTopics       = 4
+)
+
+// Proof is a decoded Polymer log proof
+type Proof struct {
+	StateRoot     [32]byte
+	Signature     []byte
+	PolymerHeight uint64
+
+	ChainID      uint32
+	BlockNumber  uint64
+	ReceiptIndex uint16
+	LogIndex     uint8
+
+	EmittingContract [addressLength]byte
+	Topics           [][topicLength]byte
+	Data             []byte
+
+	// InclusionProof is the remaining IAVL proof path, left undecoded
+	InclusionProof []byte
+}
+
+// Decode parses the binary proof layout. It only checks that the data is
+// long enough for the fields it declares; use Validate for semantic checks.
+func Decode(data []byte) (*Proof, error) {
+	if len(data) < headerLength {
+		return nil, fmt.Errorf("proof is %d bytes, shorter than the %d byte header", len(data), headerLength)
+	}
+
+	p := &Proof{}
+	copy(p.StateRoot[:], data[0:32])
+	p.Signature = append([]byte(nil), data[32:32+signatureLength]...)
+	p.PolymerHeight = binary.BigEndian.Uint64(data[97:105])
+	p.ChainID = binary.BigEndian.Uint32(data[105:109])
+	p.BlockNumber = binary.BigEndian.Uint64(data[109:117])
+	p.ReceiptIndex = binary.BigEndian.Uint16(data[117:119])
+	p.LogIndex = data[119]
+
+	numTopics := int(data[120])
+	eventEnd := int(binary.BigEndian.Uint16(data[121:123]))
+
+	if eventEnd > len(data) {
+		return nil, fmt.Errorf("event ends at byte %d but proof is only %d bytes", eventEnd, len(data))
+	}
+	minEventEnd := headerLength + addressLength + numTopics*topicLength
+	if eventEnd < minEventEnd {
+		return nil, fmt.Errorf("event ends at byte %d but %d topics need at least %d bytes", eventEnd, numTopics, minEventEnd)
+	}
+
+	offset := headerLength
+	copy(p.EmittingContract[:], data[offset:offset+addressLength])
+	offset += addressLength
+
+	p.Topics = make([][topicLength]byte, numTopics)
+	for i := range p.Topics {
+		copy(p.Topics[i][:], data[offset:offset+topicLength])
+		offset += topicLength
+	}
+
+	p.Data = append([]byte(nil), data[offset:eventEnd]...)
+	p.InclusionProof = append([]byte(nil), data[eventEnd:]...)
+
+	return p, nil
+}
+
+// Validate checks that the decoded fields are internally consistent. All
+// failures are reported together. The log index is not checked since 0 is
+// legitimate (the first log in a receipt).
+func (p *Proof) Validate() error {
+	var errs []error
+
+	if p.ChainID == 0 {
+		errs = append(errs, errors.New("source chain ID is zero"))
+	}
+	if p.BlockNumber == 0 {
+		errs = append(errs, errors.New("source block number is zero"))
+	}
+	if p.PolymerHeight == 0 {
+		errs = append(errs, errors.New("polymer height is zero"))
+	}
+	if len(p.Signature) != signatureLength {
+		errs = append(errs, fmt.Errorf("signature is %d bytes, expected %d", len(p.Signature), signatureLength))
+	}
+	if len(p.Topics) > maxTopics {
+		errs = append(errs, fmt.Errorf("event has %d topics, at most %d are allowed", len(p.Topics), maxTopics))
+	}
+	if p.EmittingContract == ([addressLength]byte{}) {
+		errs = append(errs, errors.New("emitting contract is the zero address"))
+	}
+	if len(p.InclusionProof) == 0 {
+		errs = append(errs, errors.New("inclusion proof is empty"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// DecodeText converts a proof as returned by the API, base64 or 0x-prefixed
+// hex, optionally wrapped in JSON quotes, into its binary form
+func DecodeText(text string) ([]byte, error) {
+	s := strings.TrimSpace(text)
+	s = strings.TrimSpace(strings.Trim(s, `"`))
+	if s == "" {
+		return nil, errors.New("proof is empty")
+	}
+
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		data, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("proof is not valid hex: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("proof is neither 0x-prefixed hex nor valid base64: %w", err)
+	}
+
+	return data, nil
+}
+
+// Parse decodes a proof in the textual form returned by the API
+func Parse(input []byte) (*Proof, error) {
+	data, err := DecodeText(string(input))
+	if err != nil {
+		return nil, fmt.Errorf("malformed proof encoding: %w", err)
+	}
+
+	p, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed proof: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,64 @@
+// Package redact masks credentials before they are printed, so debug output
+// can be shared without leaking API keys or RPC tokens.
+package redact
+
+import (
+	"net/http"
+	"strings"
+)
+
+// sensitiveWords are the parts of a header name that mark its value as a
+// credential, such as X-Api-Key, X-Auth-Token or Cookie
+var sensitiveWords = []string{"auth", "key", "token", "secret", "password", "session", "cookie", "signature"}
+
+// Secret masks all but the last 4 characters of secret. Secrets of 4
+// characters or fewer are masked entirely.
+func Secret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+// Headers returns a copy of h that is safe to log. The values of
+// Authorization-style headers keep their scheme and have the credentials
+// masked; the values of other headers whose names mark them as credentials
+// are masked whole.
+func Headers(h http.Header) http.Header {
+	redacted := h.Clone()
+	for name, values := range redacted {
+		if !SensitiveHeader(name) {
+			continue
+		}
+		for i, value := range values {
+			values[i] = authValue(name, value)
+		}
+	}
+
+	return redacted
+}
+
+// SensitiveHeader reports whether the header called name carries credentials
+func SensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, word := range sensitiveWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authValue masks a single header value, keeping the scheme of an
+// Authorization or Proxy-Authorization value such as "Bearer <token>"
+func authValue(name, value string) string {
+	if strings.HasSuffix(strings.ToLower(name), "authorization") {
+		if scheme, credentials, found := strings.Cut(value, " "); found {
+			return scheme + " " + Secret(credentials)
+		}
+	}
+
+	return Secret(value)
+}
@@ -0,0 +1,55 @@
+package redact
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{"", ""},
+		{"abc", "***"},
+		{"abcd", "****"},
+		{"abcde", "*bcde"},
+		{"sk-live-0123456789", "**************6789"},
+	}
+
+	for _, tt := range tests {
+		if got := Secret(tt.secret); got != tt.want {
+			t.Errorf("Secret(%q) = %q, want %q", tt.secret, got, tt.want)
+		}
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	h := http.Header{
+		"Authorization":       {"Bearer sk-live-0123456789"},
+		"Proxy-Authorization": {"Basic dXNlcjpwYXNz"},
+		"X-Api-Key":           {"key-0123456789"},
+		"X-Auth-Token":        {"tok"},
+		"Cookie":              {"session=abcdefgh"},
+		"Content-Type":        {"application/json"},
+		"User-Agent":          {"polymer-cli/1.0"},
+	}
+	original := h.Clone()
+
+	want := http.Header{
+		"Authorization":       {"Bearer **************6789"},
+		"Proxy-Authorization": {"Basic ********YXNz"},
+		"X-Api-Key":           {"**********6789"},
+		"X-Auth-Token":        {"***"},
+		"Cookie":              {"************efgh"},
+		"Content-Type":        {"application/json"},
+		"User-Agent":          {"polymer-cli/1.0"},
+	}
+	if got := Headers(h); !reflect.DeepEqual(got, want) {
+		t.Errorf("Headers() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(h, original) {
+		t.Errorf("Headers() modified its argument: %v", h)
+	}
+}
@@ -2,31 +2,154 @@ package rpc
 
 import (
 	"bytes"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math/big"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
-
-	"golang.org/x/crypto/sha3"
+	"sync/atomic"
+	"time"
+
+	"github.com/stevenlei/polymer-cli/pkg/compress"
+	"github.com/stevenlei/polymer-cli/pkg/logging"
+	"github.com/stevenlei/polymer-cli/pkg/redact"
+	"github.com/stevenlei/polymer-cli/pkg/timing"
+	"github.com/stevenlei/polymer-cli/pkg/tracelog"
 )
 
 // RPCClient represents a JSON-RPC client for Ethereum
 type RPCClient struct {
-	URL        string
+	// URLs are tried in order; later entries are only used when earlier
+	// ones fail with a connection error or 5xx response. http(s) and ws(s)
+	// URLs can be mixed.
+	URLs       []string
 	HTTPClient *http.Client
 	Debug      bool
+	// DebugOutput receives debug messages; it defaults to stderr so that
+	// stdout stays clean for command output
+	DebugOutput io.Writer
+	// Logger, when set, receives all diagnostic messages at its own level
+	// and replaces Debug and DebugOutput
+	Logger *logging.Logger
+
+	// TraceLog, when set, records every request and response as a JSON
+	// line, regardless of Debug
+	TraceLog *tracelog.Logger
+
+	// Timing, when set, reports the duration of every round trip
+	Timing *timing.Recorder
+
+	// UserAgent is sent as the User-Agent header when set
+	UserAgent string
+
+	// Headers are added to every request, e.g. for authenticated endpoints.
+	// Content-Type is only replaced when HeaderOverride is set.
+	Headers        http.Header
+	HeaderOverride bool
+
+	// AuthHeader and AuthValue, when set, carry the endpoint credentials on
+	// every request, e.g. "Authorization" and "Bearer <token>". They take
+	// precedence over Headers, and the credentials, like passwords in URLs,
+	// are masked in diagnostic messages.
+	AuthHeader string
+	AuthValue  string
+
+	// NextID returns the ID of the next JSON-RPC request. When nil each
+	// client numbers its requests 1, 2, 3, ...; set it for deterministic or
+	// globally unique IDs.
+	NextID func() int
+	lastID atomic.Int64
+
+	// MaxResponseBytes caps the size of a response body or WebSocket
+	// message; a larger one fails with ErrResponseTooLarge. Zero means
+	// DefaultMaxResponseBytes.
+	MaxResponseBytes int64
 }
 
-// NewRPCClient creates a new Ethereum RPC client
-func NewRPCClient(url string, debug bool) *RPCClient {
+// DefaultMaxResponseBytes is the largest response read when MaxResponseBytes
+// is not set
+const DefaultMaxResponseBytes = 64 << 20
+
+// NewRPCClient creates a new Ethereum RPC client. Each URL may use http(s) or
+// ws(s); WebSocket endpoints get a short-lived connection per request.
+func NewRPCClient(urls []string, debug bool) *RPCClient {
 	return &RPCClient{
-		URL:        url,
-		HTTPClient: &http.Client{},
-		Debug:      debug,
+		URLs:        urls,
+		HTTPClient:  &http.Client{},
+		Debug:       debug,
+		DebugOutput: os.Stderr,
+	}
+}
+
+// nextID returns the ID for a new JSON-RPC request
+func (c *RPCClient) nextID() int {
+	if c.NextID != nil {
+		return c.NextID()
+	}
+	return int(c.lastID.Add(1))
+}
+
+// logf writes a diagnostic message at level to Logger. Without a Logger,
+// debug messages go to DebugOutput in debug mode.
+func (c *RPCClient) logf(level logging.Level, format string, args ...interface{}) {
+	logger := c.Logger
+	if logger == nil {
+		out := c.DebugOutput
+		if out == nil {
+			out = os.Stderr
+		}
+		maxLevel := logging.LevelWarn
+		if c.Debug {
+			maxLevel = logging.LevelDebug
+		}
+		logger = logging.New(out, maxLevel)
+	}
+	if !logger.Enabled(level) {
+		return
+	}
+
+	// Never let RPC credentials reach the log, whatever is being printed
+	msg := fmt.Sprintf(format, args...)
+	for _, secret := range c.secrets() {
+		msg = strings.ReplaceAll(msg, secret, redact.Secret(secret))
 	}
+	logger.Logf(level, "%s", msg)
+}
+
+// secrets returns the credentials configured for the endpoints: the
+// AuthValue without its scheme and any password in the URLs
+func (c *RPCClient) secrets() []string {
+	var secrets []string
+	if c.AuthValue != "" {
+		if _, credentials, found := strings.Cut(c.AuthValue, " "); found && credentials != "" {
+			secrets = append(secrets, credentials)
+		} else {
+			secrets = append(secrets, c.AuthValue)
+		}
+	}
+	for _, raw := range c.URLs {
+		if u, err := url.Parse(raw); err == nil && u.User != nil {
+			if password, ok := u.User.Password(); ok && password != "" {
+				secrets = append(secrets, password)
+			}
+		}
+	}
+
+	return secrets
+}
+
+// debugf writes a debug message
+func (c *RPCClient) debugf(format string, args ...interface{}) {
+	c.logf(logging.LevelDebug, format, args...)
+}
+
+// tracef writes a message too detailed for debug mode
+func (c *RPCClient) tracef(format string, args ...interface{}) {
+	c.logf(logging.LevelTrace, format, args...)
 }
 
 // JSONRPCRequest represents a JSON-RPC request
@@ -71,6 +194,15 @@ type TransactionReceipt struct {
 	Logs             []Log  `json:"logs"`
 }
 
+// Block represents an Ethereum block header. Transactions holds hashes only.
+type Block struct {
+	Number       string   `json:"number"`
+	Hash         string   `json:"hash"`
+	ParentHash   string   `json:"parentHash"`
+	Timestamp    string   `json:"timestamp"`
+	Transactions []string `json:"transactions"`
+}
+
 // Log represents a log entry in a transaction receipt
 type Log struct {
 	LogIndex         string   `json:"logIndex"`
@@ -83,18 +215,30 @@ type Log struct {
 	Topics           []string `json:"topics"`
 }
 
-// GetTransaction fetches transaction information by hash
-func (c *RPCClient) GetTransaction(txHash string) (*Transaction, error) {
-	// Ensure the hash is prefixed with 0x
-	if !strings.HasPrefix(txHash, "0x") {
-		txHash = "0x" + txHash
-	}
+// ErrUnreachable is returned when every RPC endpoint failed with a connection
+// error or 5xx response
+var ErrUnreachable = errors.New("RPC endpoint unreachable")
 
+// ErrBatchUnsupported is returned by BatchCall when the endpoint does not
+// answer a batch request with a matching batch response
+var ErrBatchUnsupported = errors.New("RPC endpoint does not support batch requests")
+
+// ErrResponseIDMismatch is returned when a JSON-RPC response carries a
+// different ID than the request it was sent for
+var ErrResponseIDMismatch = errors.New("response ID does not match request ID")
+
+// ErrResponseTooLarge is returned when a response is larger than the client's
+// MaxResponseBytes
+var ErrResponseTooLarge = errors.New("response too large")
+
+// doRequest sends a JSON-RPC request and returns its result, failing over to
+// the next endpoint on connection errors and 5xx responses
+func (c *RPCClient) doRequest(method string, params interface{}) (json.RawMessage, error) {
 	request := JSONRPCRequest{
 		JSONRPC: "2.0",
-		ID:      1,
-		Method:  "eth_getTransactionByHash",
-		Params:  []interface{}{txHash},
+		ID:      c.nextID(),
+		Method:  method,
+		Params:  params,
 	}
 
 	reqBody, err := json.Marshal(request)
@@ -102,42 +246,224 @@ func (c *RPCClient) GetTransaction(txHash string) (*Transaction, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Sending RPC request to %s\n", c.URL)
-		fmt.Printf("DEBUG: Request body: %s\n", string(reqBody))
+	body, err := c.send(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var response JSONRPCResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// An error response with a null ID means the node could not read the
+	// request, so its error is more useful than a mismatch
+	if response.ID != request.ID && (response.ID != 0 || response.Error == nil) {
+		return nil, fmt.Errorf("%w: got %d, expected %d", ErrResponseIDMismatch, response.ID, request.ID)
+	}
+
+	if response.Error != nil {
+		return nil, fmt.Errorf("RPC returned error: %s", response.Error.Message)
+	}
+
+	return response.Result, nil
+}
+
+// send delivers an encoded request to the first endpoint that answers,
+// failing over to the next one on connection errors and 5xx responses
+func (c *RPCClient) send(reqBody []byte) ([]byte, error) {
+	if len(c.URLs) == 0 {
+		return nil, fmt.Errorf("no RPC URL configured")
+	}
+
+	var lastErr error
+	for _, url := range c.URLs {
+		body, failover, err := c.transportFor(url)(url, reqBody)
+		if err != nil {
+			if !failover {
+				return nil, err
+			}
+
+			c.debugf("RPC endpoint %s failed: %v\n", url, err)
+			lastErr = err
+			continue
+		}
+
+		return body, nil
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrUnreachable, lastErr)
+}
+
+// BatchCall sends reqs as a single JSON-RPC batch request and returns the
+// responses in the same order as reqs, matched by ID, so every request needs a
+// distinct ID. Per-request errors are left in each response's Error field.
+// ErrBatchUnsupported is returned when the node does not answer with a batch,
+// and ErrResponseIDMismatch when it answers with IDs that were not requested.
+func (c *RPCClient) BatchCall(reqs []JSONRPCRequest) ([]JSONRPCResponse, error) {
+	if len(reqs) == 0 {
+		return nil, nil
 	}
 
-	resp, err := c.HTTPClient.Post(c.URL, "application/json", bytes.NewBuffer(reqBody))
+	reqBody, err := json.Marshal(reqs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to marshal batch request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.send(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
+	}
+
+	var responses []JSONRPCResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// Nodes without batch support reply with a single error object
+		return nil, fmt.Errorf("%w: %s", ErrBatchUnsupported, strings.TrimSpace(string(body)))
+	}
+
+	requested := make(map[int]bool, len(reqs))
+	for _, req := range reqs {
+		requested[req.ID] = true
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Response status: %s\n", resp.Status)
-		fmt.Printf("DEBUG: Response body: %s\n", string(body))
+	// A response for an ID we never sent, or a second one for the same ID,
+	// means the batch cannot be trusted as a whole
+	byID := make(map[int]JSONRPCResponse, len(responses))
+	for _, resp := range responses {
+		// A null ID answers an entry the node could not parse; the request it
+		// belongs to is reported as unanswered below
+		if resp.ID == 0 && resp.Error != nil {
+			continue
+		}
+		if _, seen := byID[resp.ID]; seen || !requested[resp.ID] {
+			return nil, fmt.Errorf("%w: unexpected response ID %d in batch", ErrResponseIDMismatch, resp.ID)
+		}
+		byID[resp.ID] = resp
 	}
 
+	ordered := make([]JSONRPCResponse, len(reqs))
+	for i, req := range reqs {
+		resp, ok := byID[req.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: no response for request %d (%s)", ErrBatchUnsupported, req.ID, req.Method)
+		}
+		ordered[i] = resp
+	}
+
+	return ordered, nil
+}
+
+// post sends a request body to a single endpoint and reports whether a
+// failure should fall through to the next endpoint
+func (c *RPCClient) post(url string, reqBody []byte) ([]byte, bool, error) {
+	c.debugf("Sending RPC request to %s\n", url)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	c.TraceLog.Request("rpc", url, reqBody)
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept-Encoding", compress.AcceptEncoding)
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+	for name, values := range c.Headers {
+		if name == "Content-Type" && !c.HeaderOverride {
+			continue
+		}
+		httpReq.Header[name] = values
+	}
+	if c.AuthHeader != "" {
+		httpReq.Header.Set(c.AuthHeader, c.AuthValue)
+	}
+	c.debugf("Request headers: %v\n", redact.Headers(httpReq.Header))
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		c.TraceLog.Error("rpc", url, time.Since(start), err)
+		c.Timing.Record("rpc", reqBody, time.Since(start), err)
+		return nil, true, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	reader, err := compress.Body(resp)
+	if err != nil {
+		c.TraceLog.Error("rpc", url, time.Since(start), err)
+		c.Timing.Record("rpc", reqBody, time.Since(start), err)
+		return nil, false, err
+	}
+	body, err := readBody(reader, c.maxResponseBytes())
+	if err != nil {
+		c.TraceLog.Error("rpc", url, time.Since(start), err)
+		c.Timing.Record("rpc", reqBody, time.Since(start), err)
+		// Not a connection problem, so reporting the endpoint as
+		// unreachable would mislead
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, false, err
+		}
+		return nil, true, fmt.Errorf("failed to read response body: %w", err)
+	}
+	c.TraceLog.Response("rpc", url, resp.StatusCode, time.Since(start), body)
+	c.Timing.Record("rpc", reqBody, time.Since(start), nil)
+
+	c.debugf("Response status: %s\n", resp.Status)
+	c.tracef("Response headers: %v\n", resp.Header)
+	c.debugf("Response body: %s\n", string(body))
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("RPC request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, resp.StatusCode >= 500, fmt.Errorf("RPC request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var response JSONRPCResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	return body, false, nil
+}
+
+// maxResponseBytes returns MaxResponseBytes, or its default when unset
+func (c *RPCClient) maxResponseBytes() int64 {
+	if c.MaxResponseBytes > 0 {
+		return c.MaxResponseBytes
 	}
+	return DefaultMaxResponseBytes
+}
 
-	if response.Error != nil {
-		return nil, fmt.Errorf("RPC returned error: %s", response.Error.Message)
+// tooLarge describes a response that went over limit
+func tooLarge(limit int64) error {
+	return fmt.Errorf("%w: response exceeds %d bytes, see max-response-bytes", ErrResponseTooLarge, limit)
+}
+
+// readBody reads r up to limit bytes, failing with ErrResponseTooLarge when
+// there is more
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a longer one
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, tooLarge(limit)
+	}
+
+	return body, nil
+}
+
+// GetTransaction fetches transaction information by hash
+func (c *RPCClient) GetTransaction(txHash string) (*Transaction, error) {
+	// Ensure the hash is prefixed with 0x
+	if !strings.HasPrefix(txHash, "0x") {
+		txHash = "0x" + txHash
+	}
+
+	result, err := c.doRequest("eth_getTransactionByHash", []interface{}{txHash})
+	if err != nil {
+		return nil, err
 	}
 
 	var tx Transaction
-	if err := json.Unmarshal(response.Result, &tx); err != nil {
+	if err := json.Unmarshal(result, &tx); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
 	}
 
@@ -151,103 +477,191 @@ func (c *RPCClient) GetTransactionReceipt(txHash string) (*TransactionReceipt, e
 		txHash = "0x" + txHash
 	}
 
-	request := JSONRPCRequest{
-		JSONRPC: "2.0",
-		ID:      1,
-		Method:  "eth_getTransactionReceipt",
-		Params:  []interface{}{txHash},
-	}
-
-	reqBody, err := json.Marshal(request)
+	result, err := c.doRequest("eth_getTransactionReceipt", []interface{}{txHash})
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Sending RPC request to %s\n", c.URL)
-		fmt.Printf("DEBUG: Request body: %s\n", string(reqBody))
+	var receipt TransactionReceipt
+	if err := json.Unmarshal(result, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal receipt: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Post(c.URL, "application/json", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	return &receipt, nil
+}
+
+// GetTransactionAndReceipt fetches a transaction and its receipt in a single
+// batch request, falling back to two sequential calls when the node does not
+// support batching
+func (c *RPCClient) GetTransactionAndReceipt(txHash string) (*Transaction, *TransactionReceipt, error) {
+	// Ensure the hash is prefixed with 0x
+	if !strings.HasPrefix(txHash, "0x") {
+		txHash = "0x" + txHash
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	responses, err := c.BatchCall([]JSONRPCRequest{
+		{JSONRPC: "2.0", ID: c.nextID(), Method: "eth_getTransactionByHash", Params: []interface{}{txHash}},
+		{JSONRPC: "2.0", ID: c.nextID(), Method: "eth_getTransactionReceipt", Params: []interface{}{txHash}},
+	})
+	if errors.Is(err, ErrBatchUnsupported) {
+		c.debugf("%v, falling back to sequential requests\n", err)
+
+		tx, err := c.GetTransaction(txHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		receipt, err := c.GetTransactionReceipt(txHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+		}
+		return tx, receipt, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to get transaction and receipt: %w", err)
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Response status: %s\n", resp.Status)
-		fmt.Printf("DEBUG: Response body: %s\n", string(body))
+	if responses[0].Error != nil {
+		return nil, nil, fmt.Errorf("failed to get transaction: RPC returned error: %s", responses[0].Error.Message)
+	}
+	var tx Transaction
+	if err := json.Unmarshal(responses[0].Result, &tx); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("RPC request failed with status %d: %s", resp.StatusCode, string(body))
+	if responses[1].Error != nil {
+		return nil, nil, fmt.Errorf("failed to get transaction receipt: RPC returned error: %s", responses[1].Error.Message)
+	}
+	var receipt TransactionReceipt
+	if err := json.Unmarshal(responses[1].Result, &receipt); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal receipt: %w", err)
 	}
 
-	var response JSONRPCResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	return &tx, &receipt, nil
+}
+
+// GetBlockByHash fetches a block by its hash, without full transaction objects
+func (c *RPCClient) GetBlockByHash(hash string) (*Block, error) {
+	// Ensure the hash is prefixed with 0x
+	if !strings.HasPrefix(hash, "0x") {
+		hash = "0x" + hash
 	}
 
-	if response.Error != nil {
-		return nil, fmt.Errorf("RPC returned error: %s", response.Error.Message)
+	return c.getBlock("eth_getBlockByHash", hash)
+}
+
+// GetBlockByNumber fetches a block by its number, without full transaction objects
+func (c *RPCClient) GetBlockByNumber(number uint64) (*Block, error) {
+	return c.getBlock("eth_getBlockByNumber", fmt.Sprintf("0x%x", number))
+}
+
+// GetBlockNumberByTag resolves a block tag such as "latest", "safe" or
+// "finalized" to the number of the block it currently refers to
+func (c *RPCClient) GetBlockNumberByTag(tag string) (uint64, error) {
+	block, err := c.getBlock("eth_getBlockByNumber", tag)
+	if err != nil {
+		return 0, err
 	}
 
-	var receipt TransactionReceipt
-	if err := json.Unmarshal(response.Result, &receipt); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal receipt: %w", err)
+	number, err := HexToUint64(block.Number)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number in block: %w", err)
 	}
 
-	return &receipt, nil
+	return number, nil
 }
 
-// GetEventSignatureHash calculates the Keccak256 hash of an event signature
-func (c *RPCClient) GetEventSignatureHash(eventSignature string) (string, error) {
-	// Ethereum uses Keccak-256 for event signatures
-	hasher := sha3.NewLegacyKeccak256()
+// getBlock fetches the block identified by id using method
+func (c *RPCClient) getBlock(method, id string) (*Block, error) {
+	result, err := c.doRequest(method, []interface{}{id, false})
+	if err != nil {
+		return nil, err
+	}
+
+	var block *Block
+	if err := json.Unmarshal(result, &block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block: %w", err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+
+	return block, nil
+}
 
-	// Write the event signature to the hasher
-	_, err := hasher.Write([]byte(eventSignature))
+// GetChainID fetches the chain ID reported by the RPC endpoint
+func (c *RPCClient) GetChainID() (uint64, error) {
+	result, err := c.doRequest("eth_chainId", []interface{}{})
 	if err != nil {
-		return "", fmt.Errorf("failed to hash event signature: %w", err)
+		return 0, err
 	}
 
-	// Get the hash result
-	hash := hasher.Sum(nil)
+	var chainIDHex string
+	if err := json.Unmarshal(result, &chainIDHex); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal chain ID: %w", err)
+	}
 
-	// Format as 0x-prefixed hex string
-	hexHash := "0x" + hex.EncodeToString(hash)
+	chainID, err := HexToUint64(chainIDHex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain ID: %w", err)
+	}
 
-	return hexHash, nil
+	return chainID, nil
 }
 
-// HexToUint64 converts a hexadecimal string to uint64
+// GetEventSignatureHash calculates the Keccak256 hash of an event signature.
+// It uses no client state; see EventSignatureHash.
+func (c *RPCClient) GetEventSignatureHash(eventSignature string) (string, error) {
+	return EventSignatureHash(eventSignature)
+}
+
+// ErrEmptyHex is returned by HexToUint64 for an empty value, which is what a
+// null field such as the block number of a pending transaction decodes to
+var ErrEmptyHex = errors.New("empty hex value")
+
+// HexToUint64 converts a hexadecimal string to uint64. The "0x" or "0X"
+// prefix is optional and digits may be upper or lower case and of any
+// length. A bare "0x" is treated as 0, but an empty value returns
+// ErrEmptyHex rather than passing a missing field off as 0.
 func HexToUint64(hex string) (uint64, error) {
-	// If the hex is "0x0", just return 0
-	if hex == "0x0" {
-		return 0, nil
+	digits := strings.TrimSpace(hex)
+	if digits == "" {
+		return 0, ErrEmptyHex
 	}
 
 	// Remove "0x" prefix if present
-	if len(hex) >= 2 && hex[0:2] == "0x" {
-		hex = hex[2:]
+	if len(digits) >= 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'X') {
+		digits = digits[2:]
 	}
 
-	// Parse hex as a big integer
-	value := new(big.Int)
-	value, ok := value.SetString(hex, 16)
-	if !ok {
-		return 0, fmt.Errorf("invalid hex value: %s", hex)
+	// Some nodes return "0x" for zero
+	if digits == "" {
+		return 0, nil
+	}
+
+	for i, r := range digits {
+		if !isHexDigit(r) {
+			return 0, fmt.Errorf("invalid hex value %q: unexpected character %q at position %d", hex, r, i)
+		}
 	}
 
-	// Check if value fits in uint64
-	if !value.IsUint64() {
+	// Leading zeros don't count towards the 16 digit limit
+	digits = strings.TrimLeft(digits, "0")
+	if len(digits) > 16 {
 		return 0, fmt.Errorf("hex value too large for uint64: %s", hex)
 	}
+	if digits == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex value %q: %w", hex, err)
+	}
+
+	return value, nil
+}
 
-	return value.Uint64(), nil
+// isHexDigit reports whether r is 0-9, a-f or A-F
+func isHexDigit(r rune) bool {
+	return ('0' <= r && r <= '9') || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
 }
@@ -0,0 +1,362 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rpcServer answers every JSON-RPC request with result, echoing its ID
+func rpcServer(t *testing.T, result interface{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDebugOutputRedactsHeaders(t *testing.T) {
+	const (
+		apiKey = "rpc-key-0123456789"
+		token  = "bearer-token-abcdef"
+		auth   = "custom-auth-secret-42"
+	)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		secret string
+	}{
+		{"x-api-key header", "X-Api-Key", apiKey, apiKey},
+		{"authorization header", "Authorization", "Bearer " + token, token},
+		{"short key", "X-Api-Key", "k9z", "k9z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rpcServer(t, "0xaa37dc")
+			var out bytes.Buffer
+			client := NewRPCClient([]string{server.URL}, true)
+			client.DebugOutput = &out
+			client.Headers = http.Header{tt.header: {tt.value}}
+			client.AuthHeader = "X-Rpc-Auth"
+			client.AuthValue = auth
+
+			if _, err := client.GetChainID(); err != nil {
+				t.Fatalf("GetChainID() error: %v", err)
+			}
+
+			debug := out.String()
+			if !strings.Contains(debug, "Request headers:") {
+				t.Fatalf("debug output has no request headers:\n%s", debug)
+			}
+			for _, secret := range []string{tt.secret, auth} {
+				if strings.Contains(debug, secret) {
+					t.Errorf("debug output contains %q:\n%s", secret, debug)
+				}
+			}
+		})
+	}
+}
+
+func TestHexToUint64(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want uint64
+		err  string
+	}{
+		{hex: "0x0", want: 0},
+		{hex: "0x1", want: 1},
+		{hex: "0x1a", want: 26},
+		{hex: "0X1A", want: 26},
+		{hex: "0xaBcD", want: 0xabcd},
+		{hex: "1036640", want: 0x1036640},
+		{hex: "0x177f6f9", want: 24639225},
+		{hex: "0xabc", want: 0xabc},
+		{hex: " 0x10\n", want: 16},
+		{hex: "", err: "empty hex value"},
+		{hex: " ", err: "empty hex value"},
+		{hex: "0x", want: 0},
+		{hex: "0X", want: 0},
+		{hex: "0x0000", want: 0},
+		{hex: "0xffffffffffffffff", want: 1<<64 - 1},
+		{hex: "0x0000ffffffffffffffff", want: 1<<64 - 1},
+		{hex: "0x10000000000000000", err: "too large for uint64"},
+		{hex: "0xg1", err: "unexpected character 'g' at position 0"},
+		{hex: "0x12z", err: "unexpected character 'z' at position 2"},
+		{hex: "0x-1", err: "unexpected character '-'"},
+		{hex: "0x1 2", err: "unexpected character ' '"},
+		{hex: "0xx1", err: "unexpected character 'x'"},
+		{hex: "x1", err: "unexpected character 'x'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			got, err := HexToUint64(tt.hex)
+			if strings.TrimSpace(tt.hex) == "" && !errors.Is(err, ErrEmptyHex) {
+				t.Errorf("HexToUint64(%q) error = %v, want it to match ErrEmptyHex", tt.hex, err)
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("HexToUint64(%q) = %d, %v, want an error containing %q", tt.hex, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HexToUint64(%q) error: %v", tt.hex, err)
+			}
+			if got != tt.want {
+				t.Errorf("HexToUint64(%q) = %d, want %d", tt.hex, got, tt.want)
+			}
+		})
+	}
+}
+
+// batchServer answers every batch with the body reply builds from the IDs
+// of the requests in it
+func batchServer(t *testing.T, reply func(ids []int) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var requests []JSONRPCRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			t.Errorf("invalid batch body %q: %v", body, err)
+		}
+		ids := make([]int, len(requests))
+		for i, req := range requests {
+			ids[i] = req.ID
+		}
+		io.WriteString(w, reply(ids))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestBatchCall(t *testing.T) {
+	// The node answers out of order; BatchCall puts the results back in
+	// request order
+	server := batchServer(t, func(ids []int) string {
+		return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x2"},{"jsonrpc":"2.0","id":%d,"error":{"code":-32000,"message":"boom"}},{"jsonrpc":"2.0","id":%d,"result":"0x1"}]`, ids[1], ids[2], ids[0])
+	})
+	client := NewRPCClient([]string{server.URL}, false)
+
+	reqs := []JSONRPCRequest{
+		{JSONRPC: "2.0", ID: 11, Method: "eth_chainId", Params: []interface{}{}},
+		{JSONRPC: "2.0", ID: 12, Method: "eth_blockNumber", Params: []interface{}{}},
+		{JSONRPC: "2.0", ID: 13, Method: "eth_gasPrice", Params: []interface{}{}},
+	}
+	responses, err := client.BatchCall(reqs)
+	if err != nil {
+		t.Fatalf("BatchCall() error: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("BatchCall() returned %d responses, want 3", len(responses))
+	}
+	for i, resp := range responses {
+		if resp.ID != reqs[i].ID {
+			t.Errorf("response %d has ID %d, want %d", i, resp.ID, reqs[i].ID)
+		}
+	}
+	if string(responses[0].Result) != `"0x1"` || string(responses[1].Result) != `"0x2"` {
+		t.Errorf("results = %s, %s, want \"0x1\", \"0x2\"", responses[0].Result, responses[1].Result)
+	}
+	if responses[2].Error == nil || responses[2].Error.Message != "boom" {
+		t.Errorf("third response error = %+v, want boom", responses[2].Error)
+	}
+
+	if responses, err := client.BatchCall(nil); err != nil || responses != nil {
+		t.Errorf("BatchCall(nil) = %v, %v, want nothing", responses, err)
+	}
+}
+
+func TestBatchCallRejectsBadIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply func(ids []int) string
+		want  error
+		msg   string
+	}{
+		{
+			name: "missing ID",
+			reply: func(ids []int) string {
+				return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x1"}]`, ids[0])
+			},
+			want: ErrBatchUnsupported,
+			msg:  "no response for request 2 (eth_blockNumber)",
+		},
+		{
+			name: "duplicate ID",
+			reply: func(ids []int) string {
+				return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x1"},{"jsonrpc":"2.0","id":%d,"result":"0x1"}]`, ids[0], ids[0])
+			},
+			want: ErrResponseIDMismatch,
+			msg:  "unexpected response ID 1 in batch",
+		},
+		{
+			name: "foreign ID",
+			reply: func(ids []int) string {
+				return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x1"},{"jsonrpc":"2.0","id":99,"result":"0x2"}]`, ids[0])
+			},
+			want: ErrResponseIDMismatch,
+			msg:  "unexpected response ID 99 in batch",
+		},
+		{
+			name: "extra foreign ID",
+			reply: func(ids []int) string {
+				return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x1"},{"jsonrpc":"2.0","id":%d,"result":"0x2"},{"jsonrpc":"2.0","id":7,"result":"0x3"}]`, ids[0], ids[1])
+			},
+			want: ErrResponseIDMismatch,
+			msg:  "unexpected response ID 7 in batch",
+		},
+		{
+			name: "null ID for an unparsed entry",
+			reply: func(ids []int) string {
+				return fmt.Sprintf(`[{"jsonrpc":"2.0","id":%d,"result":"0x1"},{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}]`, ids[0])
+			},
+			want: ErrBatchUnsupported,
+			msg:  "no response for request 2",
+		},
+		{
+			name: "single error object",
+			reply: func([]int) string {
+				return `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch requests are not supported"}}`
+			},
+			want: ErrBatchUnsupported,
+			msg:  "batch requests are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := batchServer(t, tt.reply)
+			client := NewRPCClient([]string{server.URL}, false)
+
+			_, err := client.BatchCall([]JSONRPCRequest{
+				{JSONRPC: "2.0", ID: 1, Method: "eth_chainId", Params: []interface{}{}},
+				{JSONRPC: "2.0", ID: 2, Method: "eth_blockNumber", Params: []interface{}{}},
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("BatchCall() error = %v, want it to match %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("BatchCall() error = %q, want it to contain %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+// replyServer answers every single JSON-RPC request with the body reply
+// returns for the request's ID
+func replyServer(t *testing.T, reply func(id int) string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request JSONRPCRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("invalid request body %q: %v", body, err)
+		}
+		io.WriteString(w, reply(request.ID))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestResponseIDMismatch(t *testing.T) {
+	const txHash = "0x5b0f3fa2bb6d1d3a6ec21af8b1d0a0a4d3c8b5cbbf7a4c4e1f0d6a9b8c7e6d5f"
+
+	calls := []struct {
+		name   string
+		result string
+		call   func(c *RPCClient) error
+	}{
+		{"GetTransaction", `{"hash":"` + txHash + `","blockNumber":"0x1","transactionIndex":"0x0"}`, func(c *RPCClient) error {
+			_, err := c.GetTransaction(txHash)
+			return err
+		}},
+		{"GetTransactionReceipt", `{"transactionHash":"` + txHash + `","blockNumber":"0x1","transactionIndex":"0x0","logs":[]}`, func(c *RPCClient) error {
+			_, err := c.GetTransactionReceipt(txHash)
+			return err
+		}},
+	}
+
+	for _, call := range calls {
+		t.Run(call.name+" wrong ID", func(t *testing.T) {
+			server := replyServer(t, func(id int) string {
+				return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, id+1, call.result)
+			})
+			err := call.call(NewRPCClient([]string{server.URL}, false))
+			if !errors.Is(err, ErrResponseIDMismatch) {
+				t.Errorf("%s() error = %v, want it to match ErrResponseIDMismatch", call.name, err)
+			}
+		})
+
+		t.Run(call.name+" error with null ID", func(t *testing.T) {
+			server := replyServer(t, func(int) string {
+				return `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`
+			})
+			err := call.call(NewRPCClient([]string{server.URL}, false))
+			if err == nil || errors.Is(err, ErrResponseIDMismatch) || !strings.Contains(err.Error(), "RPC returned error: parse error") {
+				t.Errorf("%s() error = %v, want the server's parse error, not an ID mismatch", call.name, err)
+			}
+		})
+	}
+}
+
+func TestNextID(t *testing.T) {
+	var ids []int
+	server := replyServer(t, func(id int) string {
+		ids = append(ids, id)
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0xaa36a7"}`, id)
+	})
+	request := func(c *RPCClient) {
+		t.Helper()
+		if _, err := c.GetChainID(); err != nil {
+			t.Fatalf("GetChainID() error: %v", err)
+		}
+	}
+
+	// An injected source decides the IDs sent
+	injected := NewRPCClient([]string{server.URL}, false)
+	next := 1000
+	injected.NextID = func() int {
+		next++
+		return next
+	}
+	request(injected)
+	request(injected)
+	if want := []int{1001, 1002}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("injected IDs = %v, want %v", ids, want)
+	}
+
+	// Without one, each client counts its own requests from 1
+	ids = nil
+	a := NewRPCClient([]string{server.URL}, false)
+	b := NewRPCClient([]string{server.URL}, false)
+	request(a)
+	request(a)
+	request(b)
+	request(a)
+	if want := []int{1, 2, 1, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDs of two clients = %v, want %v", ids, want)
+	}
+}
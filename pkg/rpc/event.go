@@ -0,0 +1,302 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// EventParam represents a single parameter of an event signature
+type EventParam struct {
+	Type    string
+	Indexed bool
+	Name    string
+}
+
+// EventSignature represents a parsed event signature such as
+// "Transfer(address indexed from, address indexed to, uint256 value)"
+type EventSignature struct {
+	Name   string
+	Params []EventParam
+}
+
+// typeAliases maps Solidity shorthand types to their canonical ABI form
+var typeAliases = map[string]string{
+	"uint":   "uint256",
+	"int":    "int256",
+	"byte":   "bytes1",
+	"fixed":  "fixed128x18",
+	"ufixed": "ufixed128x18",
+}
+
+var (
+	identifierPattern  = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	arraySuffixPattern = regexp.MustCompile(`^(\[[0-9]*\])*`)
+	bracketSpace       = regexp.MustCompile(`\s*\[\s*([0-9]*)\s*\]`)
+	eventKeyword       = regexp.MustCompile(`^event\s+`)
+	anonymousSuffix    = regexp.MustCompile(`\)\s*anonymous$`)
+)
+
+// ParseEventSignature parses an event signature, accepting the relaxed forms
+// people copy from Solidity source: an optional "event" keyword, arbitrary
+// whitespace, parameter names, the "indexed" modifier and type aliases
+func ParseEventSignature(signature string) (*EventSignature, error) {
+	sig := strings.TrimSpace(signature)
+	sig = strings.TrimSpace(strings.TrimSuffix(sig, ";"))
+	sig = eventKeyword.ReplaceAllString(sig, "")
+
+	// Anonymous events don't emit their signature hash as a topic
+	if anonymousSuffix.MatchString(sig) {
+		return nil, fmt.Errorf("invalid event signature %q: anonymous events have no signature topic to match", signature)
+	}
+
+	open := strings.Index(sig, "(")
+	if open < 0 || !strings.HasSuffix(sig, ")") {
+		return nil, fmt.Errorf("invalid event signature %q: expected Name(type,...)", signature)
+	}
+
+	name := strings.TrimSpace(sig[:open])
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid event name %q", name)
+	}
+
+	parts, err := splitParams(sig[open+1 : len(sig)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid event signature %q: %w", signature, err)
+	}
+
+	event := &EventSignature{Name: name}
+	for _, part := range parts {
+		param, err := parseEventParam(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event signature %q: %w", signature, err)
+		}
+		event.Params = append(event.Params, param)
+	}
+
+	return event, nil
+}
+
+// Canonical returns the signature in the form that is hashed into topic 0,
+// e.g. "Transfer(address,address,uint256)"
+func (e *EventSignature) Canonical() string {
+	types := make([]string, len(e.Params))
+	for i, param := range e.Params {
+		types[i] = param.Type
+	}
+
+	return e.Name + "(" + strings.Join(types, ",") + ")"
+}
+
+// CanonicalEventSignature normalizes an event signature so that it hashes to
+// the same topic as the compiler-generated one
+func CanonicalEventSignature(signature string) (string, error) {
+	event, err := ParseEventSignature(signature)
+	if err != nil {
+		return "", err
+	}
+
+	return event.Canonical(), nil
+}
+
+// EventSignatureHash calculates the Keccak256 hash of an event signature, the
+// topic a log emitted for it carries first. The signature is hashed as given;
+// use CanonicalEventSignature first for user input.
+func EventSignatureHash(eventSignature string) (string, error) {
+	// Ethereum uses Keccak-256 for event signatures
+	hasher := sha3.NewLegacyKeccak256()
+
+	// Write the event signature to the hasher
+	if _, err := hasher.Write([]byte(eventSignature)); err != nil {
+		return "", fmt.Errorf("failed to hash event signature: %w", err)
+	}
+
+	// Format as 0x-prefixed hex string
+	return "0x" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// SplitEventSignatures splits a list of event signatures separated by commas
+// or newlines, ignoring the commas and line breaks between parameters, e.g.
+// "Transfer(address,address,uint256),Approval(address,address,uint256)" or a
+// Solidity declaration spread over several lines. Blank entries are dropped.
+func SplitEventSignatures(list string) []string {
+	var signatures []string
+	depth, start := 0, 0
+	add := func(sig string) {
+		if sig = strings.TrimSpace(sig); sig != "" {
+			signatures = append(signatures, sig)
+		}
+	}
+
+	for i, ch := range list {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',', '\n':
+			// Inside parentheses both separate parameters instead
+			if depth <= 0 {
+				add(list[start:i])
+				start = i + 1
+				depth = 0
+			}
+		}
+	}
+	add(list[start:])
+
+	return signatures
+}
+
+// parseEventParam parses a single "type [indexed] [name]" parameter
+func parseEventParam(param string) (EventParam, error) {
+	param = bracketSpace.ReplaceAllString(strings.TrimSpace(param), "[$1]")
+
+	var typ, rest string
+	if strings.HasPrefix(param, "(") {
+		// Tuple type: find the matching closing paren, then any array suffix
+		end, err := matchingParen(param)
+		if err != nil {
+			return EventParam{}, err
+		}
+		suffix := arraySuffixPattern.FindString(param[end+1:])
+		typ = param[:end+1+len(suffix)]
+		rest = param[end+1+len(suffix):]
+	} else {
+		fields := strings.Fields(param)
+		if len(fields) == 0 {
+			return EventParam{}, fmt.Errorf("empty parameter")
+		}
+		typ = fields[0]
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	canonical, err := canonicalType(typ)
+	if err != nil {
+		return EventParam{}, err
+	}
+
+	result := EventParam{Type: canonical}
+	for _, word := range strings.Fields(rest) {
+		switch {
+		case word == "indexed" && !result.Indexed && result.Name == "":
+			result.Indexed = true
+		case result.Name == "" && identifierPattern.MatchString(word):
+			result.Name = word
+		default:
+			return EventParam{}, fmt.Errorf("unexpected %q in parameter %q", word, param)
+		}
+	}
+
+	return result, nil
+}
+
+// canonicalType expands type aliases, including inside tuples and arrays
+func canonicalType(typ string) (string, error) {
+	typ = strings.TrimSpace(typ)
+
+	if strings.HasPrefix(typ, "(") {
+		end, err := matchingParen(typ)
+		if err != nil {
+			return "", err
+		}
+		suffix := typ[end+1:]
+		if arraySuffixPattern.FindString(suffix) != suffix {
+			return "", fmt.Errorf("invalid type %q", typ)
+		}
+
+		parts, err := splitParams(typ[1:end])
+		if err != nil {
+			return "", err
+		}
+
+		components := make([]string, len(parts))
+		for i, part := range parts {
+			// Tuple components may carry names too, so parse them like parameters
+			component, err := parseEventParam(part)
+			if err != nil {
+				return "", err
+			}
+			if component.Indexed {
+				return "", fmt.Errorf("tuple component %q cannot be indexed", part)
+			}
+			components[i] = component.Type
+		}
+
+		return "(" + strings.Join(components, ",") + ")" + suffix, nil
+	}
+
+	base, suffix := typ, ""
+	if i := strings.Index(typ, "["); i >= 0 {
+		base, suffix = typ[:i], typ[i:]
+	}
+	if arraySuffixPattern.FindString(suffix) != suffix || !identifierPattern.MatchString(base) {
+		return "", fmt.Errorf("invalid type %q", typ)
+	}
+
+	if alias, ok := typeAliases[base]; ok {
+		base = alias
+	}
+
+	return base + suffix, nil
+}
+
+// splitParams splits a comma-separated parameter list, ignoring commas
+// nested inside tuple parentheses
+func splitParams(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i, ch := range list {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	parts = append(parts, list[start:])
+
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return nil, fmt.Errorf("empty parameter")
+		}
+	}
+
+	return parts, nil
+}
+
+// matchingParen returns the index of the parenthesis closing the one at s[0]
+func matchingParen(s string) (int, error) {
+	depth := 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unbalanced parentheses in %q", s)
+}
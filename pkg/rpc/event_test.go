@@ -0,0 +1,285 @@
+package rpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	approvalTopic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+)
+
+func TestParseEventSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		canonical string
+		params    []EventParam
+	}{
+		{
+			name:      "canonical",
+			signature: "Transfer(address,address,uint256)",
+			canonical: "Transfer(address,address,uint256)",
+			params:    []EventParam{{Type: "address"}, {Type: "address"}, {Type: "uint256"}},
+		},
+		{
+			name:      "names and indexed",
+			signature: "Transfer(address indexed from, address indexed to, uint256 value)",
+			canonical: "Transfer(address,address,uint256)",
+			params: []EventParam{
+				{Type: "address", Indexed: true, Name: "from"},
+				{Type: "address", Indexed: true, Name: "to"},
+				{Type: "uint256", Name: "value"},
+			},
+		},
+		{
+			name:      "indexed without name",
+			signature: "Approval(address indexed, address indexed, uint)",
+			canonical: "Approval(address,address,uint256)",
+			params:    []EventParam{{Type: "address", Indexed: true}, {Type: "address", Indexed: true}, {Type: "uint256"}},
+		},
+		{
+			name:      "solidity declaration",
+			signature: "  event Transfer( address indexed from ,address indexed to,uint value );",
+			canonical: "Transfer(address,address,uint256)",
+			params: []EventParam{
+				{Type: "address", Indexed: true, Name: "from"},
+				{Type: "address", Indexed: true, Name: "to"},
+				{Type: "uint256", Name: "value"},
+			},
+		},
+		{
+			name:      "aliases",
+			signature: "Values(uint a, int b, byte c, fixed d, ufixed e)",
+			canonical: "Values(uint256,int256,bytes1,fixed128x18,ufixed128x18)",
+			params: []EventParam{
+				{Type: "uint256", Name: "a"}, {Type: "int256", Name: "b"}, {Type: "bytes1", Name: "c"},
+				{Type: "fixed128x18", Name: "d"}, {Type: "ufixed128x18", Name: "e"},
+			},
+		},
+		{
+			name:      "arrays",
+			signature: "TransferBatch(address indexed operator, uint[] ids, uint256 [ 3 ] values, bytes32[][2] data)",
+			canonical: "TransferBatch(address,uint256[],uint256[3],bytes32[][2])",
+			params: []EventParam{
+				{Type: "address", Indexed: true, Name: "operator"}, {Type: "uint256[]", Name: "ids"},
+				{Type: "uint256[3]", Name: "values"}, {Type: "bytes32[][2]", Name: "data"},
+			},
+		},
+		{
+			name:      "tuples",
+			signature: "Order((address maker, uint amount) order, (uint,(bool,int)[])[] indexed fills)",
+			canonical: "Order((address,uint256),(uint256,(bool,int256)[])[])",
+			params: []EventParam{
+				{Type: "(address,uint256)", Name: "order"},
+				{Type: "(uint256,(bool,int256)[])[]", Indexed: true, Name: "fills"},
+			},
+		},
+		{
+			name:      "no parameters",
+			signature: "Paused()",
+			canonical: "Paused()",
+		},
+		{
+			name:      "dollar and underscore names",
+			signature: "_Log$(string $msg)",
+			canonical: "_Log$(string)",
+			params:    []EventParam{{Type: "string", Name: "$msg"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseEventSignature(tt.signature)
+			if err != nil {
+				t.Fatalf("ParseEventSignature(%q) error: %v", tt.signature, err)
+			}
+			if got := event.Canonical(); got != tt.canonical {
+				t.Errorf("Canonical() = %q, want %q", got, tt.canonical)
+			}
+			if !reflect.DeepEqual(event.Params, tt.params) {
+				t.Errorf("Params = %+v, want %+v", event.Params, tt.params)
+			}
+		})
+	}
+}
+
+func TestParseEventSignatureErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		want      string
+	}{
+		{"empty", "", "expected Name(type,...)"},
+		{"no parentheses", "Transfer", "expected Name(type,...)"},
+		{"unclosed", "Transfer(address,address", "expected Name(type,...)"},
+		{"bad name", "1Transfer(address)", "invalid event name"},
+		{"missing name", "(address)", "invalid event name"},
+		{"empty parameter", "Transfer(address,,uint256)", "empty parameter"},
+		{"trailing comma", "Transfer(address,)", "empty parameter"},
+		{"unbalanced tuple", "Order((address,uint256)", "unbalanced parentheses"},
+		{"extra closing paren", "Order(address))", "unbalanced parentheses"},
+		{"bad type", "Transfer(addr-ess)", "invalid type"},
+		{"bad array", "Transfer(uint256[x])", "invalid type"},
+		{"indexed twice", "Transfer(address indexed indexed from)", "unexpected"},
+		{"indexed after name", "Transfer(address from indexed)", "unexpected \"indexed\""},
+		{"two names", "Transfer(address from to)", "unexpected \"to\""},
+		{"indexed tuple component", "Order((address indexed maker))", "cannot be indexed"},
+		{"anonymous", "event Transfer(address indexed from) anonymous;", "anonymous events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEventSignature(tt.signature)
+			if err == nil {
+				t.Fatalf("ParseEventSignature(%q) succeeded, want error containing %q", tt.signature, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseEventSignature(%q) error = %q, want it to contain %q", tt.signature, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalEventSignatureHash(t *testing.T) {
+	tests := []struct {
+		signature string
+		topic     string
+	}{
+		{"Transfer(address,address,uint256)", transferTopic},
+		{"Transfer(address indexed from, address indexed to, uint256 value)", transferTopic},
+		{"event Transfer(address indexed from, address indexed to, uint value);", transferTopic},
+		{"Transfer(address,address,uint)", transferTopic},
+		{"Approval(address indexed owner, address indexed spender, uint256 value)", approvalTopic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			canonical, err := CanonicalEventSignature(tt.signature)
+			if err != nil {
+				t.Fatalf("CanonicalEventSignature(%q) error: %v", tt.signature, err)
+			}
+			hash, err := EventSignatureHash(canonical)
+			if err != nil {
+				t.Fatalf("EventSignatureHash(%q) error: %v", canonical, err)
+			}
+			if hash != tt.topic {
+				t.Errorf("hash of %q = %s, want %s", tt.signature, hash, tt.topic)
+			}
+		})
+	}
+}
+
+func TestKnownEventSignaturesAreCanonical(t *testing.T) {
+	for _, sig := range KnownEventSignatures {
+		canonical, err := CanonicalEventSignature(sig)
+		if err != nil {
+			t.Errorf("CanonicalEventSignature(%q) error: %v", sig, err)
+			continue
+		}
+		if canonical != sig {
+			t.Errorf("CanonicalEventSignature(%q) = %q, want it unchanged", sig, canonical)
+		}
+	}
+}
+
+func TestSplitEventSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want []string
+	}{
+		{
+			name: "single",
+			list: "Transfer(address,address,uint256)",
+			want: []string{"Transfer(address,address,uint256)"},
+		},
+		{
+			name: "commas between signatures",
+			list: "Transfer(address,address,uint256), Approval(address,address,uint256)",
+			want: []string{"Transfer(address,address,uint256)", "Approval(address,address,uint256)"},
+		},
+		{
+			name: "newlines between signatures",
+			list: "Transfer(address,address,uint256)\nApproval(address,address,uint256)\n",
+			want: []string{"Transfer(address,address,uint256)", "Approval(address,address,uint256)"},
+		},
+		{
+			name: "tuple commas",
+			list: "Order((address,uint256)[],bool),Paused()",
+			want: []string{"Order((address,uint256)[],bool)", "Paused()"},
+		},
+		{
+			name: "multi-line declaration",
+			list: "event Transfer(\n    address indexed from,\n    address indexed to,\n    uint256 value\n);",
+			want: []string{"event Transfer(\n    address indexed from,\n    address indexed to,\n    uint256 value\n);"},
+		},
+		{
+			name: "several multi-line declarations",
+			list: "event Transfer(\n  address indexed from,\n  address indexed to,\n  uint256 value\n);\nevent Approval(\n  address indexed owner,\n  address indexed spender,\n  uint256 value\n);\n",
+			want: []string{
+				"event Transfer(\n  address indexed from,\n  address indexed to,\n  uint256 value\n);",
+				"event Approval(\n  address indexed owner,\n  address indexed spender,\n  uint256 value\n);",
+			},
+		},
+		{
+			name: "blank entries",
+			list: " ,\n\nTransfer(address,address,uint256),, ",
+			want: []string{"Transfer(address,address,uint256)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitEventSignatures(tt.list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitEventSignatures(%q) = %q, want %q", tt.list, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolidityDeclarations(t *testing.T) {
+	tests := []struct {
+		name        string
+		declaration string
+		topic       string
+	}{
+		{
+			name:        "one line",
+			declaration: "event Transfer(address indexed from, address indexed to, uint256 value);",
+			topic:       transferTopic,
+		},
+		{
+			name:        "multi-line",
+			declaration: "event Transfer(\n    address indexed from,\n    address indexed to,\n    uint256 value\n);",
+			topic:       transferTopic,
+		},
+		{
+			name:        "tabs and Windows line endings",
+			declaration: "\tevent Approval(\r\n\t\taddress indexed owner,\r\n\t\taddress indexed spender,\r\n\t\tuint value\r\n\t);\r\n",
+			topic:       approvalTopic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sigs := SplitEventSignatures(tt.declaration)
+			if len(sigs) != 1 {
+				t.Fatalf("SplitEventSignatures(%q) = %q, want one signature", tt.declaration, sigs)
+			}
+			canonical, err := CanonicalEventSignature(sigs[0])
+			if err != nil {
+				t.Fatalf("CanonicalEventSignature(%q) error: %v", sigs[0], err)
+			}
+			hash, err := EventSignatureHash(canonical)
+			if err != nil {
+				t.Fatalf("EventSignatureHash(%q) error: %v", canonical, err)
+			}
+			if hash != tt.topic {
+				t.Errorf("hash of %q = %s, want %s", canonical, hash, tt.topic)
+			}
+		})
+	}
+}
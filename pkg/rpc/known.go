@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"strings"
+	"sync"
+)
+
+// KnownEventSignatures is a bundled list of common token and DEX events used
+// to name logs by their first topic
+var KnownEventSignatures = []string{
+	"Transfer(address,address,uint256)",
+	"Approval(address,address,uint256)",
+	"ApprovalForAll(address,address,bool)",
+	"TransferSingle(address,address,address,uint256,uint256)",
+	"TransferBatch(address,address,address,uint256[],uint256[])",
+	"OwnershipTransferred(address,address)",
+	"Deposit(address,uint256)",
+	"Withdrawal(address,uint256)",
+	"Sync(uint112,uint112)",
+	"Swap(address,uint256,uint256,uint256,uint256,address)",
+	"Swap(address,address,int256,int256,uint160,uint128,int24)",
+	"Mint(address,uint256,uint256)",
+	"Burn(address,uint256,uint256,address)",
+}
+
+var (
+	knownTopics     map[string]string
+	knownTopicsOnce sync.Once
+)
+
+// LookupEventSignature returns the known event signature whose hash is
+// topic, if there is one
+func LookupEventSignature(topic string) (string, bool) {
+	knownTopicsOnce.Do(func() {
+		knownTopics = make(map[string]string, len(KnownEventSignatures))
+		for _, sig := range KnownEventSignatures {
+			// The bundled signatures are canonical, so hashing cannot fail
+			hash, _ := EventSignatureHash(sig)
+			knownTopics[hash] = sig
+		}
+	})
+
+	sig, ok := knownTopics[strings.ToLower(topic)]
+	return sig, ok
+}
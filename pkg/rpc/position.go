@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogPosition locates one log of a transaction receipt, the same way a proof
+// request addresses it, and lays out its topics and data
+type LogPosition struct {
+	TxHash      string `json:"txHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	TxIndex     uint64 `json:"txIndex"`
+
+	// LogIndex is the position of the log in the receipt, the --log-index of
+	// a proof request
+	LogIndex uint `json:"logIndex"`
+	// GlobalLogIndex is the node's logIndex field, the position of the log
+	// among all logs of the block
+	GlobalLogIndex uint64 `json:"globalLogIndex"`
+	// FirstGlobalLogIndex is the GlobalLogIndex of the transaction's first
+	// log, i.e. how many logs earlier transactions in the block emitted
+	FirstGlobalLogIndex uint64 `json:"firstGlobalLogIndex"`
+	// TxLogCount is the number of logs the transaction emitted
+	TxLogCount int `json:"txLogCount"`
+
+	Address string        `json:"address"`
+	Topics  []LogTopic    `json:"topics"`
+	Data    []LogDataWord `json:"data"`
+	// DataBytes is the length of the data in bytes, which need not be a
+	// multiple of 32
+	DataBytes int `json:"dataBytes"`
+}
+
+// LogTopic is one topic of a log. Param names the event parameter the topic
+// holds when an event signature with indexed parameters was given.
+type LogTopic struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
+	Param string `json:"param,omitempty"`
+}
+
+// LogDataWord is one 32-byte word of the log data, at byte Offset. A final
+// short word is returned as is.
+type LogDataWord struct {
+	Offset int    `json:"offset"`
+	Value  string `json:"value"`
+}
+
+// ComputeLogPosition locates the log at logIndex in receipt. When event is
+// not nil and describes the log (its hash is topic 0 and it has one indexed
+// parameter per further topic), the topics are labelled with the parameters
+// they hold.
+func ComputeLogPosition(receipt *TransactionReceipt, logIndex uint, event *EventSignature) (*LogPosition, error) {
+	if int(logIndex) >= len(receipt.Logs) {
+		return nil, fmt.Errorf("log index %d is out of range: transaction %s has %d logs", logIndex, receipt.TransactionHash, len(receipt.Logs))
+	}
+	log := receipt.Logs[logIndex]
+
+	blockNumber, err := HexToUint64(receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt block number %q: %w", receipt.BlockNumber, err)
+	}
+	txIndex, err := HexToUint64(receipt.TransactionIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt transaction index %q: %w", receipt.TransactionIndex, err)
+	}
+	globalIndex, err := HexToUint64(log.LogIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid logIndex %q of log %d: %w", log.LogIndex, logIndex, err)
+	}
+
+	// Logs of a receipt are numbered consecutively within the block, so the
+	// first one sits logIndex places before this one
+	if globalIndex < uint64(logIndex) {
+		return nil, fmt.Errorf("log %d has block logIndex %d, lower than its position in the receipt", logIndex, globalIndex)
+	}
+	first := globalIndex - uint64(logIndex)
+	if len(receipt.Logs) > 0 && receipt.Logs[0].LogIndex != "" {
+		if got, err := HexToUint64(receipt.Logs[0].LogIndex); err == nil && got != first {
+			return nil, fmt.Errorf("receipt logs are not numbered consecutively: log 0 has logIndex %d, expected %d", got, first)
+		}
+	}
+
+	position := &LogPosition{
+		TxHash:              receipt.TransactionHash,
+		BlockNumber:         blockNumber,
+		BlockHash:           receipt.BlockHash,
+		TxIndex:             txIndex,
+		LogIndex:            logIndex,
+		GlobalLogIndex:      globalIndex,
+		FirstGlobalLogIndex: first,
+		TxLogCount:          len(receipt.Logs),
+		Address:             log.Address,
+	}
+
+	labels := topicLabels(event, log.Topics)
+	position.Topics = make([]LogTopic, len(log.Topics))
+	for i, topic := range log.Topics {
+		position.Topics[i] = LogTopic{Index: i, Value: topic}
+		if labels != nil {
+			position.Topics[i].Param = labels[i]
+		}
+	}
+
+	data := strings.TrimPrefix(strings.TrimPrefix(log.Data, "0x"), "0X")
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("invalid data of log %d: odd number of hex digits", logIndex)
+	}
+	position.DataBytes = len(data) / 2
+	position.Data = []LogDataWord{}
+	for offset := 0; offset < len(data); offset += 64 {
+		end := min(offset+64, len(data))
+		position.Data = append(position.Data, LogDataWord{Offset: offset / 2, Value: "0x" + data[offset:end]})
+	}
+
+	return position, nil
+}
+
+// topicLabels names each of topics after event: the signature hash first,
+// then the indexed parameters in order. It returns nil when event is nil or
+// does not describe the topics.
+func topicLabels(event *EventSignature, topics []string) []string {
+	if event == nil || len(topics) == 0 {
+		return nil
+	}
+	if hash, err := EventSignatureHash(event.Canonical()); err != nil || !strings.EqualFold(hash, topics[0]) {
+		return nil
+	}
+
+	labels := []string{"signature " + event.Canonical()}
+	for _, param := range event.Params {
+		if !param.Indexed {
+			continue
+		}
+		label := param.Type
+		if param.Name != "" {
+			label += " " + param.Name
+		}
+		// Indexed values that are not a single word are stored as their hash
+		if isDynamicType(param.Type) {
+			label += " (keccak256)"
+		}
+		labels = append(labels, label)
+	}
+
+	if len(labels) != len(topics) {
+		return nil
+	}
+	return labels
+}
+
+// isDynamicType reports whether an indexed parameter of canonical type typ is
+// stored in its topic as a keccak256 hash: strings, bytes, arrays and tuples
+func isDynamicType(typ string) bool {
+	return typ == "string" || typ == "bytes" || strings.HasSuffix(typ, "]") || strings.HasPrefix(typ, "(")
+}
@@ -0,0 +1,253 @@
+package rpc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	fromTopic = "0x000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	toTopic   = "0x000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	oneWord   = "0x0000000000000000000000000000000000000000000000000de0b6b3a7640000"
+)
+
+// positionReceipt is transaction 4 of block 24639225, whose three logs
+// follow seven logs of earlier transactions
+func positionReceipt() *TransactionReceipt {
+	return &TransactionReceipt{
+		TransactionHash:  "0x" + strings.Repeat("ab", 32),
+		TransactionIndex: "0x4",
+		BlockNumber:      "0x177f6f9",
+		BlockHash:        "0x" + strings.Repeat("cd", 32),
+		Logs: []Log{
+			{LogIndex: "0x7", Address: "0x1111111111111111111111111111111111111111", Topics: []string{approvalTopic, fromTopic, toTopic}, Data: oneWord},
+			{LogIndex: "0x8", Address: "0x2222222222222222222222222222222222222222", Topics: []string{transferTopic, fromTopic, toTopic}, Data: oneWord},
+			{LogIndex: "0x9", Address: "0x3333333333333333333333333333333333333333", Data: "0x" + strings.Repeat("11", 32) + strings.Repeat("22", 8)},
+		},
+	}
+}
+
+func mustParseEvent(t *testing.T, signature string) *EventSignature {
+	t.Helper()
+
+	event, err := ParseEventSignature(signature)
+	if err != nil {
+		t.Fatalf("ParseEventSignature(%q) error: %v", signature, err)
+	}
+	return event
+}
+
+func TestComputeLogPosition(t *testing.T) {
+	receipt := positionReceipt()
+	event := mustParseEvent(t, "Transfer(address indexed from, address indexed to, uint256 value)")
+
+	got, err := ComputeLogPosition(receipt, 1, event)
+	if err != nil {
+		t.Fatalf("ComputeLogPosition() error: %v", err)
+	}
+
+	want := &LogPosition{
+		TxHash:              receipt.TransactionHash,
+		BlockNumber:         24639225,
+		BlockHash:           receipt.BlockHash,
+		TxIndex:             4,
+		LogIndex:            1,
+		GlobalLogIndex:      8,
+		FirstGlobalLogIndex: 7,
+		TxLogCount:          3,
+		Address:             "0x2222222222222222222222222222222222222222",
+		Topics: []LogTopic{
+			{Index: 0, Value: transferTopic, Param: "signature Transfer(address,address,uint256)"},
+			{Index: 1, Value: fromTopic, Param: "address from"},
+			{Index: 2, Value: toTopic, Param: "address to"},
+		},
+		Data:      []LogDataWord{{Offset: 0, Value: oneWord}},
+		DataBytes: 32,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComputeLogPosition() = %+v, want %+v", got, want)
+	}
+}
+
+func TestComputeLogPositionPositions(t *testing.T) {
+	receipt := positionReceipt()
+
+	for i, wantGlobal := range []uint64{7, 8, 9} {
+		got, err := ComputeLogPosition(receipt, uint(i), nil)
+		if err != nil {
+			t.Fatalf("ComputeLogPosition(%d) error: %v", i, err)
+		}
+		if got.LogIndex != uint(i) || got.GlobalLogIndex != wantGlobal || got.FirstGlobalLogIndex != 7 {
+			t.Errorf("log %d: LogIndex %d, GlobalLogIndex %d, FirstGlobalLogIndex %d, want %d, %d, 7",
+				i, got.LogIndex, got.GlobalLogIndex, got.FirstGlobalLogIndex, i, wantGlobal)
+		}
+	}
+
+	// The first transaction of a block starts at zero
+	first := &TransactionReceipt{TransactionIndex: "0x0", BlockNumber: "0x1", Logs: []Log{{LogIndex: "0x0"}, {LogIndex: "0x1"}}}
+	got, err := ComputeLogPosition(first, 1, nil)
+	if err != nil {
+		t.Fatalf("ComputeLogPosition() error: %v", err)
+	}
+	if got.GlobalLogIndex != 1 || got.FirstGlobalLogIndex != 0 || got.TxIndex != 0 {
+		t.Errorf("ComputeLogPosition() = %+v, want global index 1 after none", got)
+	}
+}
+
+func TestComputeLogPositionData(t *testing.T) {
+	got, err := ComputeLogPosition(positionReceipt(), 2, nil)
+	if err != nil {
+		t.Fatalf("ComputeLogPosition() error: %v", err)
+	}
+
+	want := []LogDataWord{
+		{Offset: 0, Value: "0x" + strings.Repeat("11", 32)},
+		{Offset: 32, Value: "0x" + strings.Repeat("22", 8)},
+	}
+	if !reflect.DeepEqual(got.Data, want) || got.DataBytes != 40 {
+		t.Errorf("Data = %+v (%d bytes), want %+v (40 bytes)", got.Data, got.DataBytes, want)
+	}
+	if len(got.Topics) != 0 {
+		t.Errorf("Topics = %+v, want none", got.Topics)
+	}
+
+	empty := positionReceipt()
+	empty.Logs[2].Data = "0x"
+	if got, err = ComputeLogPosition(empty, 2, nil); err != nil {
+		t.Fatalf("ComputeLogPosition() error: %v", err)
+	}
+	if got.Data == nil || len(got.Data) != 0 || got.DataBytes != 0 {
+		t.Errorf("Data = %#v (%d bytes), want an empty list", got.Data, got.DataBytes)
+	}
+}
+
+func TestComputeLogPositionLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		logIndex  uint
+		// indexed, when set, replaces the log's topics with the event hash
+		// followed by these
+		indexed []string
+		want    []string
+	}{
+		{
+			name:      "unnamed parameters",
+			signature: "Transfer(address indexed, address indexed, uint256)",
+			logIndex:  1,
+			want:      []string{"signature Transfer(address,address,uint256)", "address", "address"},
+		},
+		{
+			name:      "other event",
+			signature: "Transfer(address indexed from, address indexed to, uint256 value)",
+			logIndex:  0,
+			want:      []string{"", "", ""},
+		},
+		{
+			name:      "indexed count differs",
+			signature: "Transfer(address indexed from, address to, uint256 value)",
+			logIndex:  1,
+			want:      []string{"", "", ""},
+		},
+		{
+			name:      "dynamic indexed parameter",
+			signature: "Named(string indexed name)",
+			indexed:   []string{toTopic},
+			want:      []string{"signature Named(string)", "string name (keccak256)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := mustParseEvent(t, tt.signature)
+			receipt := positionReceipt()
+			if tt.indexed != nil {
+				hash, err := EventSignatureHash(event.Canonical())
+				if err != nil {
+					t.Fatal(err)
+				}
+				receipt.Logs[tt.logIndex].Topics = append([]string{hash}, tt.indexed...)
+			}
+
+			got, err := ComputeLogPosition(receipt, tt.logIndex, event)
+			if err != nil {
+				t.Fatalf("ComputeLogPosition() error: %v", err)
+			}
+			labels := make([]string, len(got.Topics))
+			for i, topic := range got.Topics {
+				labels[i] = topic.Param
+			}
+			if !reflect.DeepEqual(labels, tt.want) {
+				t.Errorf("topic labels = %q, want %q", labels, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeLogPositionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*TransactionReceipt)
+		logIndex uint
+		want     string
+	}{
+		{
+			name:     "out of range",
+			logIndex: 3,
+			want:     "log index 3 is out of range",
+		},
+		{
+			name:   "bad block number",
+			modify: func(r *TransactionReceipt) { r.BlockNumber = "0xzz" },
+			want:   "invalid receipt block number",
+		},
+		{
+			name:   "pending receipt",
+			modify: func(r *TransactionReceipt) { r.BlockNumber, r.TransactionIndex = "", "" },
+			want:   "invalid receipt block number \"\": empty hex value",
+		},
+		{
+			name:   "bad transaction index",
+			modify: func(r *TransactionReceipt) { r.TransactionIndex = "four" },
+			want:   "invalid receipt transaction index",
+		},
+		{
+			name:     "bad logIndex",
+			modify:   func(r *TransactionReceipt) { r.Logs[1].LogIndex = "0xq" },
+			logIndex: 1,
+			want:     "invalid logIndex",
+		},
+		{
+			name:     "logIndex below the receipt position",
+			modify:   func(r *TransactionReceipt) { r.Logs[2].LogIndex = "0x1" },
+			logIndex: 2,
+			want:     "lower than its position in the receipt",
+		},
+		{
+			name:     "gap in the numbering",
+			modify:   func(r *TransactionReceipt) { r.Logs[2].LogIndex = "0xa" },
+			logIndex: 2,
+			want:     "not numbered consecutively: log 0 has logIndex 7, expected 8",
+		},
+		{
+			name:     "odd data",
+			modify:   func(r *TransactionReceipt) { r.Logs[2].Data = "0x123" },
+			logIndex: 2,
+			want:     "odd number of hex digits",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt := positionReceipt()
+			if tt.modify != nil {
+				tt.modify(receipt)
+			}
+			_, err := ComputeLogPosition(receipt, tt.logIndex, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ComputeLogPosition() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package rpc
+
+// transport sends an encoded JSON-RPC request to a single endpoint and returns
+// the raw response, reporting whether a failure should fall through to the
+// next endpoint. Marshaling and response handling stay in doRequest so they
+// are shared by every transport.
+type transport func(url string, reqBody []byte) ([]byte, bool, error)
+
+// transportFor picks the transport matching the scheme of url
+func (c *RPCClient) transportFor(url string) transport {
+	if IsWebSocketURL(url) {
+		return c.postWebSocket
+	}
+
+	return c.post
+}
@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultWebSocketTimeout bounds a WebSocket round trip when the HTTP client
+// has no timeout of its own
+const defaultWebSocketTimeout = 30 * time.Second
+
+// IsWebSocketURL reports whether raw is an absolute ws or wss URL with a host
+func IsWebSocketURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "ws" || u.Scheme == "wss") && u.Host != ""
+}
+
+// postWebSocket sends a request body over a new WebSocket connection to a
+// single endpoint and reads back one response message. Any failure falls
+// through to the next endpoint.
+func (c *RPCClient) postWebSocket(endpoint string, reqBody []byte) ([]byte, bool, error) {
+	c.debugf("Sending RPC request to %s\n", endpoint)
+	c.debugf("Request body: %s\n", string(reqBody))
+
+	timeout := defaultWebSocketTimeout
+	if c.HTTPClient != nil && c.HTTPClient.Timeout > 0 {
+		timeout = c.HTTPClient.Timeout
+	}
+
+	c.TraceLog.Request("rpc", endpoint, reqBody)
+	start := time.Now()
+
+	// fail records a failed round trip and marks it for failover
+	fail := func(format string, err error) ([]byte, bool, error) {
+		c.TraceLog.Error("rpc", endpoint, time.Since(start), err)
+		c.Timing.Record("rpc", reqBody, time.Since(start), err)
+		return nil, true, fmt.Errorf(format, err)
+	}
+
+	header := c.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if c.UserAgent != "" {
+		header.Set("User-Agent", c.UserAgent)
+	}
+	if c.AuthHeader != "" {
+		header.Set(c.AuthHeader, c.AuthValue)
+	}
+
+	// Connect through the same proxy and TLS settings as HTTP requests
+	dialer := websocket.Dialer{HandshakeTimeout: timeout, Proxy: http.ProxyFromEnvironment}
+	if c.HTTPClient != nil {
+		if t, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+			dialer.Proxy = t.Proxy
+			dialer.TLSClientConfig = t.TLSClientConfig
+		}
+	}
+	conn, _, err := dialer.Dial(endpoint, header)
+	if err != nil {
+		return fail("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return fail("failed to send request: %w", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, reqBody); err != nil {
+		return fail("failed to send request: %w", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return fail("failed to read response: %w", err)
+	}
+	limit := c.maxResponseBytes()
+	conn.SetReadLimit(limit)
+	_, body, err := conn.ReadMessage()
+	if errors.Is(err, websocket.ErrReadLimit) {
+		err = tooLarge(limit)
+		c.TraceLog.Error("rpc", endpoint, time.Since(start), err)
+		c.Timing.Record("rpc", reqBody, time.Since(start), err)
+		return nil, false, err
+	}
+	if err != nil {
+		return fail("failed to read response: %w", err)
+	}
+	c.TraceLog.Response("rpc", endpoint, 0, time.Since(start), body)
+	c.Timing.Record("rpc", reqBody, time.Since(start), nil)
+
+	c.debugf("Response body: %s\n", string(body))
+
+	// Close politely; the node may already have gone away, which is fine
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	return body, false, nil
+}
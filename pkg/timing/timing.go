@@ -0,0 +1,89 @@
+// Package timing reports how long each API and RPC call takes, for
+// performance debugging.
+package timing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder prints one line per call and keeps a running total. A nil
+// *Recorder discards everything, so clients can call it unconditionally. It
+// is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.Writer
+	calls int
+	total time.Duration
+}
+
+// New creates a Recorder writing to w
+func New(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Record prints the duration of one round trip whose JSON-RPC request was
+// reqBody. err is the transport error, if the call got no response.
+func (r *Recorder) Record(source string, reqBody []byte, elapsed time.Duration, err error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	r.total += elapsed
+
+	line := fmt.Sprintf("TIMING: %s %s %s", source, method(reqBody), millis(elapsed))
+	if err != nil {
+		line += " (failed)"
+	}
+	fmt.Fprintln(r.w, line)
+}
+
+// Summary prints the number of calls and their total duration
+func (r *Recorder) Summary() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.w, "TIMING: total %d calls in %s\n", r.calls, millis(r.total))
+}
+
+// millis formats d in milliseconds, keeping sub-millisecond calls visible
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// request is the part of a JSON-RPC request that names the call
+type request struct {
+	Method string `json:"method"`
+}
+
+// method returns the JSON-RPC method of a request body, or the methods of a
+// batch joined with "+"
+func method(reqBody []byte) string {
+	var req request
+	if err := json.Unmarshal(reqBody, &req); err == nil && req.Method != "" {
+		return req.Method
+	}
+
+	var batch []request
+	if err := json.Unmarshal(reqBody, &batch); err == nil && len(batch) > 0 {
+		methods := make([]string, len(batch))
+		for i, r := range batch {
+			methods[i] = r.Method
+		}
+		return strings.Join(methods, "+")
+	}
+
+	return "unknown"
+}
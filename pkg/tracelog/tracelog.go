@@ -0,0 +1,84 @@
+// Package tracelog writes HTTP request and response traces as JSON lines, so
+// a session can be kept and analysed later independently of debug output.
+package tracelog
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Entry is one line of the trace
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Source is the client that made the request, "api" or "rpc"
+	Source string `json:"source"`
+	// Kind is "request", "response" or "error"
+	Kind       string          `json:"kind"`
+	URL        string          `json:"url"`
+	Status     int             `json:"status,omitempty"`
+	DurationMs int64           `json:"durationMs,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// Logger serializes entries to a writer. A nil *Logger discards everything,
+// so clients can call it unconditionally. It is safe for concurrent use.
+//
+// Only URLs and bodies are recorded, never headers, so the API key sent in
+// the Authorization header does not end up in the trace.
+type Logger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New creates a Logger writing to w
+func New(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Request records a request body sent to url
+func (l *Logger) Request(source, url string, body []byte) {
+	l.write(Entry{Source: source, Kind: "request", URL: url, Body: l.body(body)})
+}
+
+// Response records the response to a request sent to url
+func (l *Logger) Response(source, url string, status int, elapsed time.Duration, body []byte) {
+	l.write(Entry{Source: source, Kind: "response", URL: url, Status: status, DurationMs: elapsed.Milliseconds(), Body: l.body(body)})
+}
+
+// Error records a request to url that failed without a response
+func (l *Logger) Error(source, url string, elapsed time.Duration, err error) {
+	l.write(Entry{Source: source, Kind: "error", URL: url, DurationMs: elapsed.Milliseconds(), Error: err.Error()})
+}
+
+// body embeds a JSON body as-is and anything else as a JSON string
+func (l *Logger) body(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	if json.Valid(body) {
+		return append(json.RawMessage(nil), body...)
+	}
+
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+func (l *Logger) write(e Entry) {
+	if l == nil {
+		return
+	}
+
+	e.Time = time.Now().UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(line, '\n'))
+}